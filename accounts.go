@@ -1,6 +1,7 @@
 package dbapi
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -11,30 +12,97 @@ type AccountsService struct {
 	client *Client
 }
 
+// An Account is a cash account of the user.
+type Account struct {
+	Iban               IBAN    `json:"iban,omitempty"`
+	Balance            Money   `json:"balance,omitempty"`
+	ProductDescription Product `json:"productDescription,omitempty"`
+}
+
 // Accounts are the cash accounts of the user.
-type Accounts []struct {
-	Iban               string  `json:"iban,omitempty"`
-	Balance            float64 `json:"balance,omitempty"`
-	ProductDescription string  `json:"productDescription,omitempty"`
+type Accounts []Account
+
+// Product is the product description of an account as shown to the user.
+// Products which aren't known to this package are kept as returned by the API.
+type Product string
+
+// Available products.
+const (
+	// PersonalAccount is a personal current account (persönliches Konto).
+	PersonalAccount Product = "persönliches Konto"
+)
+
+func (p Product) String() string {
+	return string(p)
+}
+
+// Known reports whether the product is one of the products defined by this
+// package.
+func (p Product) Known() bool {
+	return p == PersonalAccount
 }
 
 // GetAll reads all cash accounts of the current user. Only current accounts and
 // accounts in the currency EUR are returned.
 func (s *AccountsService) GetAll() (*Accounts, *Response, error) {
+	return s.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll but uses the context ctx for the request.
+func (s *AccountsService) GetAllContext(ctx context.Context) (*Accounts, *Response, error) {
 	u := "/cashAccounts"
 	r := new(Accounts)
 
-	resp, err := s.client.Call(http.MethodGet, u, nil, r)
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
 	return r, resp, err
 }
 
 // Get reads the specified cash account of the current user. If given IBAN is
-// not valid or does not represent an account of the current user, an empty
-// result is returned.
-func (s *AccountsService) Get(iban string) (*Accounts, *Response, error) {
+// malformed, an error wrapping ErrInvalidIBAN is returned without calling the
+// API. Its checksum isn't checked (see IBAN.ValidateStructure). If it does not
+// represent an account of the current user, an empty result is returned.
+func (s *AccountsService) Get(iban IBAN) (*Accounts, *Response, error) {
+	return s.GetContext(context.Background(), iban)
+}
+
+// GetContext is like Get but uses the context ctx for the request.
+func (s *AccountsService) GetContext(ctx context.Context, iban IBAN) (*Accounts, *Response, error) {
+	iban, err := parseRequestIBAN(iban)
+	if err != nil {
+		return nil, nil, err
+	}
 	u := fmt.Sprintf("/cashAccounts?iban=%s", iban)
 	r := new(Accounts)
 
-	resp, err := s.client.Call(http.MethodGet, u, nil, r)
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
 	return r, resp, err
 }
+
+// ByIBAN returns the account with the given IBAN. The second return value
+// reports whether the account was found.
+func (a Accounts) ByIBAN(iban IBAN) (Account, bool) {
+	for _, acc := range a {
+		if acc.Iban == iban {
+			return acc, true
+		}
+	}
+	return Account{}, false
+}
+
+// IBANs returns the IBANs of the accounts.
+func (a Accounts) IBANs() []IBAN {
+	ibans := make([]IBAN, len(a))
+	for i, acc := range a {
+		ibans[i] = acc.Iban
+	}
+	return ibans
+}
+
+// Total returns the sum of the balances of the accounts.
+func (a Accounts) Total() Money {
+	var total Money
+	for _, acc := range a {
+		total = total.Add(acc.Balance)
+	}
+	return total
+}
@@ -1,6 +1,9 @@
 package dbapi
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
@@ -11,9 +14,9 @@ func TestAccountsService_GetAll(t *testing.T) {
 	defer teardown()
 
 	exp := &Accounts{
-		{Iban: "DE10000000000000000453", Balance: 31236.95, ProductDescription: "persönliches Konto"},
-		{Iban: "DE10000000000000000454", Balance: 250, ProductDescription: "persönliches Konto"},
-		{Iban: "DE10000000000000000455", Balance: 100, ProductDescription: "persönliches Konto"},
+		{Iban: "DE10000000000000000453", Balance: eur("31236.95"), ProductDescription: "persönliches Konto"},
+		{Iban: "DE10000000000000000454", Balance: eur("250"), ProductDescription: "persönliches Konto"},
+		{Iban: "DE10000000000000000455", Balance: eur("100"), ProductDescription: "persönliches Konto"},
 	}
 
 	testMux.HandleFunc("/v1/cashAccounts", func(w http.ResponseWriter, r *http.Request) {
@@ -31,7 +34,7 @@ func TestAccountsService_Get(t *testing.T) {
 	defer teardown()
 
 	exp := &Accounts{
-		{Iban: "DE10000000000000000454", Balance: 250, ProductDescription: "persönliches Konto"},
+		{Iban: "DE10000000000000000454", Balance: eur("250"), ProductDescription: "persönliches Konto"},
 	}
 
 	testMux.HandleFunc("/v1/cashAccounts", func(w http.ResponseWriter, r *http.Request) {
@@ -43,3 +46,60 @@ func TestAccountsService_Get(t *testing.T) {
 	ok(t, err)
 	equals(t, exp, act)
 }
+
+func TestAccountsService_GetAllContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/cashAccounts", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := testClient.Accounts.GetAllContext(ctx)
+	equals(t, context.Canceled, err)
+}
+
+func TestAccountsService_Get_InvalidIBAN(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/cashAccounts", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be made.")
+	})
+
+	_, resp, err := testClient.Accounts.Get("DE1000000000000000045")
+	assert(t, errors.Is(err, ErrInvalidIBAN), "Expected ErrInvalidIBAN, got %v.", err)
+	assert(t, resp == nil, "Expected no response.")
+}
+
+func TestAccounts_Helpers(t *testing.T) {
+	accounts := Accounts{
+		{Iban: "DE10000000000000000453", Balance: eur("31236.95")},
+		{Iban: "DE10000000000000000454", Balance: eur("250")},
+		{Iban: "DE10000000000000000455", Balance: eur("-100.5")},
+	}
+
+	acc, found := accounts.ByIBAN("DE10000000000000000454")
+	assert(t, found, "expected account to be found")
+	equals(t, eur("250"), acc.Balance)
+
+	_, found = accounts.ByIBAN("DE89370400440532013000")
+	assert(t, !found, "expected account not to be found")
+
+	equals(t, []IBAN{"DE10000000000000000453", "DE10000000000000000454", "DE10000000000000000455"}, accounts.IBANs())
+	equals(t, eur("31386.45"), accounts.Total())
+	equals(t, Money{}, Accounts{}.Total())
+}
+
+func TestProduct(t *testing.T) {
+	var accounts Accounts
+	ok(t, json.Unmarshal([]byte(`[{"productDescription":"persönliches Konto"},{"productDescription":"Sparkonto"}]`), &accounts))
+
+	equals(t, PersonalAccount, accounts[0].ProductDescription)
+	assert(t, accounts[0].ProductDescription.Known(), "expected product to be known")
+	equals(t, "Sparkonto", accounts[1].ProductDescription.String())
+	assert(t, !accounts[1].ProductDescription.Known(), "expected product to be unknown")
+}
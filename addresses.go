@@ -1,6 +1,10 @@
 package dbapi
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"strings"
+)
 
 // The AddressesService binds to the HTTP endpoints which belong to the
 // addresses resource.
@@ -8,23 +12,171 @@ type AddressesService struct {
 	client *Client
 }
 
+// An Address is a postal address of the user. Country is an ISO 3166-1 alpha-2
+// country code.
+type Address struct {
+	Street      string      `json:"street,omitempty"`
+	HouseNumber HouseNumber `json:"houseNumber,omitempty"`
+	ZipCode     PostalCode  `json:"zip,omitempty"`
+	City        string      `json:"city,omitempty"`
+	Country     string      `json:"country,omitempty"`
+	Type        AddressType `json:"type,omitempty"`
+}
+
 // Addresses are the users addresses.
-type Addresses []struct {
-	Street      string `json:"street,omitempty"`
-	HouseNumber int64  `json:"houseNumber,string,omitempty"`
-	ZipCode     int64  `json:"zip,string,omitempty"`
-	City        string `json:"city,omitempty"`
-	Country     string `json:"country,omitempty"`
-	Type        string `json:"type,omitempty"`
+type Addresses []Address
+
+// AddressType is the purpose of an address. Types which aren't known to this
+// package are kept as returned by the API.
+type AddressType string
+
+// Available address types.
+const (
+	// MailingAddress is the address the bank sends mail to.
+	MailingAddress AddressType = "MAILING_ADDRESS"
+	// RegistrationAddress is the address the user is registered at.
+	RegistrationAddress AddressType = "REGISTRATION_ADDRESS"
+)
+
+func (t AddressType) String() string {
+	return string(t)
+}
+
+// Known reports whether the address type is one of the types defined by this
+// package.
+func (t AddressType) Known() bool {
+	switch t {
+	case MailingAddress, RegistrationAddress:
+		return true
+	}
+	return false
 }
 
 // Get reads all addresses of the current user. Usually a user has exactly two
-// addresses with the types MAILING_ADDRESS and REGISTRATION_ADDRESS
+// addresses with the types MailingAddress and RegistrationAddress
 // respectively. Otherwise those two addresses are often identical.
 func (s *AddressesService) Get() (*Addresses, *Response, error) {
+	return s.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses the context ctx for the request.
+func (s *AddressesService) GetContext(ctx context.Context) (*Addresses, *Response, error) {
 	u := "/addresses"
 	r := new(Addresses)
 
-	resp, err := s.client.Call(http.MethodGet, u, nil, r)
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
 	return r, resp, err
 }
+
+// ByType returns the first address of the given type. The second return value
+// reports whether such an address was found.
+func (a Addresses) ByType(t AddressType) (Address, bool) {
+	for _, addr := range a {
+		if addr.Type == t {
+			return addr, true
+		}
+	}
+	return Address{}, false
+}
+
+// Mailing returns the mailing address of the user. The second return value
+// reports whether it was found.
+func (a Addresses) Mailing() (Address, bool) {
+	return a.ByType(MailingAddress)
+}
+
+// Registration returns the registration address of the user. The second return
+// value reports whether it was found.
+func (a Addresses) Registration() (Address, bool) {
+	return a.ByType(RegistrationAddress)
+}
+
+// Validate checks the postal code of the address against the format of its
+// country. An address without country is checked against the format of
+// Germany.
+func (a Address) Validate() error {
+	return a.ZipCode.Validate(a.country(""))
+}
+
+// numberFirst lists the countries in which the house number is written before
+// the street.
+var numberFirst = map[string]bool{
+	"AU": true, "CA": true, "FR": true, "GB": true, "IE": true, "LU": true,
+	"US": true,
+}
+
+// countryNames are the English names of some countries, which are used in the
+// last line of labels for international mail.
+var countryNames = map[string]string{
+	"AT": "Austria", "BE": "Belgium", "CH": "Switzerland", "CZ": "Czech Republic",
+	"DE": "Germany", "DK": "Denmark", "ES": "Spain", "FI": "Finland",
+	"FR": "France", "GB": "United Kingdom", "IT": "Italy", "LI": "Liechtenstein",
+	"LU": "Luxembourg", "NL": "Netherlands", "NO": "Norway", "PL": "Poland",
+	"PT": "Portugal", "SE": "Sweden", "US": "United States",
+}
+
+// Label renders the address as postal label for the recipient, one line per
+// address line. origin is the ISO 3166-1 alpha-2 code of the country the mail
+// is sent from; an address without country is considered to be in the origin
+// country, or in Germany if origin is empty.
+//
+// Domestic German addresses follow DIN 5008: the street and house number are
+// followed by the postal code and city, without a blank line. For international
+// mail, the city and the name of the destination country are written in upper
+// case, as required by the Universal Postal Union. The order of the street and
+// house number and the position of the postal code follow the conventions of
+// the destination country.
+func (a Address) Label(recipient, origin string) string {
+	country := a.country(origin)
+	international := origin != "" && strings.ToUpper(origin) != country
+	city := a.City
+	if international {
+		city = strings.ToUpper(city)
+	}
+
+	street := a.Street + " " + a.HouseNumber.String()
+	if numberFirst[country] {
+		street = a.HouseNumber.String() + " " + a.Street
+	}
+
+	lines := []string{recipient, strings.TrimSpace(street)}
+	switch country {
+	case "GB":
+		lines = append(lines, strings.ToUpper(a.City), string(a.ZipCode))
+	case "US", "CA", "AU":
+		lines = append(lines, strings.TrimSpace(city+" "+string(a.ZipCode)))
+	default:
+		lines = append(lines, strings.TrimSpace(string(a.ZipCode)+" "+city))
+	}
+	if international {
+		name, ok := countryNames[country]
+		if !ok {
+			name = country
+		}
+		lines = append(lines, strings.ToUpper(name))
+	}
+
+	var b strings.Builder
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(l)
+	}
+	return b.String()
+}
+
+// country returns the upper case country code of the address, falling back to
+// origin and then Germany if the address has no country.
+func (a Address) country(origin string) string {
+	switch {
+	case a.Country != "":
+		return strings.ToUpper(a.Country)
+	case origin != "":
+		return strings.ToUpper(origin)
+	}
+	return "DE"
+}
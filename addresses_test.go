@@ -1,6 +1,7 @@
 package dbapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
@@ -11,8 +12,8 @@ func TestAddressesService_Get(t *testing.T) {
 	defer teardown()
 
 	exp := &Addresses{
-		{City: "Frankfurt", HouseNumber: 19, Street: "Große Bockenheimer Straße", Type: "MAILING_ADDRESS", ZipCode: 60311},
-		{City: "Frankfurt", HouseNumber: 19, Street: "Große Bockenheimer Straße", Type: "REGISTRATION_ADDRESS", ZipCode: 60311},
+		{City: "Frankfurt", HouseNumber: HouseNumber{Number: "19"}, Street: "Große Bockenheimer Straße", Type: "MAILING_ADDRESS", ZipCode: "60311"},
+		{City: "Frankfurt", HouseNumber: HouseNumber{Number: "19"}, Street: "Große Bockenheimer Straße", Type: "REGISTRATION_ADDRESS", ZipCode: "60311"},
 	}
 
 	testMux.HandleFunc("/v1/addresses", func(w http.ResponseWriter, r *http.Request) {
@@ -24,3 +25,91 @@ func TestAddressesService_Get(t *testing.T) {
 	ok(t, err)
 	equals(t, exp, act)
 }
+
+func TestAddressesService_Get_PreservesData(t *testing.T) {
+	setup()
+	defer teardown()
+
+	exp := &Addresses{
+		{City: "Dresden", HouseNumber: HouseNumber{Number: "12", Suffix: "a"}, Street: "Theaterplatz", Type: "MAILING_ADDRESS", ZipCode: "01067", Country: "DE"},
+		{City: "Frankfurt", HouseNumber: HouseNumber{Number: "19"}, Street: "Große Bockenheimer Straße", Type: "REGISTRATION_ADDRESS", ZipCode: "60311"},
+	}
+
+	testMux.HandleFunc("/v1/addresses", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"city":"Dresden","houseNumber":"12a","street":"Theaterplatz","type":"MAILING_ADDRESS","zip":"01067","country":"DE"},{"city":"Frankfurt","houseNumber":19,"street":"Große Bockenheimer Straße","type":"REGISTRATION_ADDRESS","zip":60311}]`)
+	})
+
+	act, _, err := testClient.Addresses.Get()
+	ok(t, err)
+	equals(t, exp, act)
+}
+
+func TestAddress_Label(t *testing.T) {
+	mockData := []struct {
+		addr      Address
+		recipient string
+		origin    string
+		exp       string
+	}{
+		{
+			Address{Street: "Theaterplatz", HouseNumber: HouseNumber{"12", "a"}, ZipCode: "01067", City: "Dresden", Country: "DE"},
+			"Claudia Klar", "DE",
+			"Claudia Klar\nTheaterplatz 12a\n01067 Dresden",
+		},
+		{
+			Address{Street: "Große Bockenheimer Straße", HouseNumber: HouseNumber{Number: "19"}, ZipCode: "60311", City: "Frankfurt"},
+			"Claudia Klar", "",
+			"Claudia Klar\nGroße Bockenheimer Straße 19\n60311 Frankfurt",
+		},
+		{
+			Address{Street: "Theaterplatz", HouseNumber: HouseNumber{"12", "a"}, ZipCode: "01067", City: "Dresden", Country: "DE"},
+			"Claudia Klar", "CH",
+			"Claudia Klar\nTheaterplatz 12a\n01067 DRESDEN\nGERMANY",
+		},
+		{
+			Address{Street: "rue de Rivoli", HouseNumber: HouseNumber{Number: "99"}, ZipCode: "75001", City: "Paris", Country: "FR"},
+			"", "DE",
+			"99 rue de Rivoli\n75001 PARIS\nFRANCE",
+		},
+		{
+			Address{Street: "Downing Street", HouseNumber: HouseNumber{Number: "10"}, ZipCode: "SW1A 2AA", City: "London", Country: "GB"},
+			"", "DE",
+			"10 Downing Street\nLONDON\nSW1A 2AA\nUNITED KINGDOM",
+		},
+	}
+
+	for _, tt := range mockData {
+		equals(t, tt.exp, tt.addr.Label(tt.recipient, tt.origin))
+	}
+}
+
+func TestAddress_Validate(t *testing.T) {
+	ok(t, Address{ZipCode: "01067"}.Validate())
+	ok(t, Address{ZipCode: "1010", Country: "AT"}.Validate())
+
+	err := Address{ZipCode: "1067"}.Validate()
+	assert(t, errors.Is(err, ErrInvalidPostalCode), "expected ErrInvalidPostalCode, got %v", err)
+}
+
+func TestAddresses_ByType(t *testing.T) {
+	addrs := Addresses{
+		{City: "Frankfurt", Type: RegistrationAddress},
+		{City: "Dresden", Type: MailingAddress},
+		{City: "Berlin", Type: "HOLIDAY_ADDRESS"},
+	}
+
+	mailing, found := addrs.Mailing()
+	assert(t, found, "expected mailing address to be found")
+	equals(t, "Dresden", mailing.City)
+
+	registration, found := addrs.Registration()
+	assert(t, found, "expected registration address to be found")
+	equals(t, "Frankfurt", registration.City)
+
+	_, found = Addresses{}.Mailing()
+	assert(t, !found, "expected no mailing address")
+
+	assert(t, MailingAddress.Known(), "expected MailingAddress to be known")
+	assert(t, !addrs[2].Type.Known(), "expected HOLIDAY_ADDRESS to be unknown")
+	equals(t, "HOLIDAY_ADDRESS", addrs[2].Type.String())
+}
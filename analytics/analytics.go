@@ -0,0 +1,290 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+// A Summary aggregates the amounts of transactions.
+type Summary struct {
+	// Income is the sum of all credits.
+	Income dbapi.Money
+	// Spending is the sum of all debits as positive amount.
+	Spending dbapi.Money
+	// Net is the sum of all amounts, which is Income minus Spending.
+	Net dbapi.Money
+	// Count is the number of transactions.
+	Count int
+}
+
+// add adds the transaction to the summary.
+func (s *Summary) add(t dbapi.Transaction) {
+	switch {
+	case t.IsCredit():
+		s.Income = s.Income.Add(t.Amount)
+	case t.IsDebit():
+		s.Spending = s.Spending.Sub(t.Amount)
+	}
+	s.Net = s.Net.Add(t.Amount)
+	s.Count++
+}
+
+// A MonthlySummary aggregates the transactions of a month.
+type MonthlySummary struct {
+	Year  int
+	Month time.Month
+	Summary
+
+	// IncomeDelta, SpendingDelta and NetDelta are the changes compared to the
+	// previous month. They are zero for the first month of a report.
+	IncomeDelta   dbapi.Money
+	SpendingDelta dbapi.Money
+	NetDelta      dbapi.Money
+
+	// AverageIncome and AverageSpending are the running averages per month
+	// from the first month of the report up to and including this month.
+	AverageIncome   dbapi.Money
+	AverageSpending dbapi.Money
+}
+
+// A WeeklySummary aggregates the transactions of an ISO 8601 week, which
+// starts on Monday.
+type WeeklySummary struct {
+	Year  int
+	Week  int
+	Start dbapi.Date
+	Summary
+}
+
+// A CategorySummary aggregates the transactions of a category.
+type CategorySummary struct {
+	Category Category
+	Summary
+	// Share is the share of the category in the total spending, between 0 and
+	// 1.
+	Share float64
+}
+
+// A CounterPartySummary aggregates the transactions of a counterparty.
+type CounterPartySummary struct {
+	CounterParty string
+	Summary
+}
+
+// An AccountSummary aggregates the transactions of an account.
+type AccountSummary struct {
+	IBAN dbapi.IBAN
+	Summary
+}
+
+// A Report holds the aggregations of transactions computed by an Analyzer.
+type Report struct {
+	// From and To are the booking dates of the first and last transaction.
+	// Transactions without booking date are left out.
+	From, To dbapi.Date
+	// Total aggregates all transactions.
+	Total Summary
+	// Undated aggregates the transactions without booking date. They are
+	// part of all summaries except the monthly and weekly ones.
+	Undated Summary
+	// Months are the months from From to To in chronological order, including
+	// months without transactions.
+	Months []MonthlySummary
+	// Weeks are the weeks from From to To in chronological order, including
+	// weeks without transactions.
+	Weeks []WeeklySummary
+	// Categories are ordered by spending, highest first. They are empty if the
+	// Analyzer has no Categorizer.
+	Categories []CategorySummary
+	// CounterParties are ordered by spending, highest first.
+	CounterParties []CounterPartySummary
+	// Accounts are ordered by IBAN.
+	Accounts []AccountSummary
+}
+
+// An Analyzer aggregates transactions into a Report. The zero value is ready to
+// use. An Analyzer must not be modified while it is used.
+type Analyzer struct {
+	// Categorizer categorizes the transactions for the category summaries. If
+	// it is nil, the report has no category summaries.
+	Categorizer *Categorizer
+	// Exclude, if set, excludes transactions from the report. Transfers
+	// between the accounts of the user are excluded beforehand with
+	// Transfers.Exclude instead, which tells identical transactions apart.
+	Exclude func(dbapi.Transaction) bool
+	// CounterParty, if set, returns the name transactions are grouped by in the
+	// counterparty summaries. By default, the counterparty name is used with
+	// whitespace normalized. Use CounterPartyNormalizer.CounterParty to group
+	// by merchant.
+	CounterParty func(dbapi.Transaction) string
+}
+
+// Analyze aggregates the transactions into a report.
+func (a *Analyzer) Analyze(t dbapi.Transactions) *Report {
+	r := new(Report)
+	months := make(map[[2]int]*MonthlySummary)
+	weeks := make(map[[2]int]*WeeklySummary)
+	categories := make(map[Category]*CategorySummary)
+	counterParties := make(map[string]*CounterPartySummary)
+	accounts := make(map[dbapi.IBAN]*AccountSummary)
+
+	for _, tx := range t {
+		if a.Exclude != nil && a.Exclude(tx) {
+			continue
+		}
+		r.Total.add(tx)
+
+		if tx.BookingDate.IsZero() {
+			r.Undated.add(tx)
+		} else {
+			if r.From.IsZero() || tx.BookingDate.Before(r.From) {
+				r.From = tx.BookingDate
+			}
+			if r.To.IsZero() || tx.BookingDate.After(r.To) {
+				r.To = tx.BookingDate
+			}
+
+			mk := [2]int{tx.BookingDate.Year, int(tx.BookingDate.Month)}
+			if months[mk] == nil {
+				months[mk] = &MonthlySummary{Year: mk[0], Month: time.Month(mk[1])}
+			}
+			months[mk].add(tx)
+
+			y, w := tx.BookingDate.In(time.UTC).ISOWeek()
+			if weeks[[2]int{y, w}] == nil {
+				weeks[[2]int{y, w}] = &WeeklySummary{Year: y, Week: w}
+			}
+			weeks[[2]int{y, w}].add(tx)
+		}
+
+		if a.Categorizer != nil {
+			c := a.Categorizer.Categorize(tx)
+			if categories[c] == nil {
+				categories[c] = &CategorySummary{Category: c}
+			}
+			categories[c].add(tx)
+		}
+
+		name := a.counterParty(tx)
+		if counterParties[strings.ToLower(name)] == nil {
+			counterParties[strings.ToLower(name)] = &CounterPartySummary{CounterParty: name}
+		}
+		counterParties[strings.ToLower(name)].add(tx)
+
+		if accounts[tx.OriginIBAN] == nil {
+			accounts[tx.OriginIBAN] = &AccountSummary{IBAN: tx.OriginIBAN}
+		}
+		accounts[tx.OriginIBAN].add(tx)
+	}
+	if r.Total.Count == 0 {
+		return r
+	}
+
+	if !r.From.IsZero() {
+		r.Months = monthlySummaries(r.From, r.To, months)
+		r.Weeks = weeklySummaries(r.From, r.To, weeks)
+	}
+
+	for _, c := range categories {
+		if !r.Total.Spending.IsZero() {
+			c.Share = float64(c.Spending.Minor()) / float64(r.Total.Spending.Minor())
+		}
+		r.Categories = append(r.Categories, *c)
+	}
+	sort.Slice(r.Categories, func(i, j int) bool {
+		if c := r.Categories[i].Spending.Cmp(r.Categories[j].Spending); c != 0 {
+			return c > 0
+		}
+		return r.Categories[i].Category < r.Categories[j].Category
+	})
+
+	for _, c := range counterParties {
+		r.CounterParties = append(r.CounterParties, *c)
+	}
+	sort.Slice(r.CounterParties, func(i, j int) bool {
+		if c := r.CounterParties[i].Spending.Cmp(r.CounterParties[j].Spending); c != 0 {
+			return c > 0
+		}
+		return r.CounterParties[i].CounterParty < r.CounterParties[j].CounterParty
+	})
+
+	for _, acc := range accounts {
+		r.Accounts = append(r.Accounts, *acc)
+	}
+	sort.Slice(r.Accounts, func(i, j int) bool {
+		return r.Accounts[i].IBAN < r.Accounts[j].IBAN
+	})
+	return r
+}
+
+// counterParty returns the name the transaction is grouped by.
+func (a *Analyzer) counterParty(t dbapi.Transaction) string {
+	if a.CounterParty != nil {
+		return a.CounterParty(t)
+	}
+	return dbapi.NormalizeSpace(t.CounterPartyName)
+}
+
+// monthlySummaries returns the summaries of all months from the date from to
+// the date to and computes their deltas and running averages.
+func monthlySummaries(from, to dbapi.Date, months map[[2]int]*MonthlySummary) []MonthlySummary {
+	var (
+		r                       []MonthlySummary
+		prev                    MonthlySummary
+		totalIncome, totalSpent dbapi.Money
+	)
+	last := dbapi.NewDate(to.Year, to.Month, 1)
+	for d := dbapi.NewDate(from.Year, from.Month, 1); !d.After(last); d = d.AddMonths(1) {
+		m := MonthlySummary{Year: d.Year, Month: d.Month}
+		if s := months[[2]int{d.Year, int(d.Month)}]; s != nil {
+			m = *s
+		}
+		if len(r) > 0 {
+			m.IncomeDelta = m.Income.Sub(prev.Income)
+			m.SpendingDelta = m.Spending.Sub(prev.Spending)
+			m.NetDelta = m.Net.Sub(prev.Net)
+		}
+		totalIncome = totalIncome.Add(m.Income)
+		totalSpent = totalSpent.Add(m.Spending)
+		m.AverageIncome = average(totalIncome, len(r)+1)
+		m.AverageSpending = average(totalSpent, len(r)+1)
+
+		r = append(r, m)
+		prev = m
+	}
+	return r
+}
+
+// weeklySummaries returns the summaries of all weeks from the date from to the
+// date to.
+func weeklySummaries(from, to dbapi.Date, weeks map[[2]int]*WeeklySummary) []WeeklySummary {
+	var r []WeeklySummary
+	// ISO weeks start on Monday.
+	start := from.AddDays(-(int(from.Weekday()) + 6) % 7)
+	for d := start; !d.After(to); d = d.AddDays(7) {
+		y, w := d.In(time.UTC).ISOWeek()
+		s := WeeklySummary{Year: y, Week: w}
+		if ws := weeks[[2]int{y, w}]; ws != nil {
+			s = *ws
+		}
+		s.Start = d
+		r = append(r, s)
+	}
+	return r
+}
+
+// average returns the sum divided by n, rounded half away from zero to the
+// minor unit.
+func average(sum dbapi.Money, n int) dbapi.Money {
+	minor, d := sum.Minor(), int64(n)
+	q := minor / d
+	if r := minor % d; 2*r >= d {
+		q++
+	} else if 2*r <= -d {
+		q--
+	}
+	return dbapi.NewMoney(q, sum.Currency())
+}
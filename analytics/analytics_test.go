@@ -0,0 +1,218 @@
+package analytics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+var (
+	// testMux is the HTTP request multiplexer used with the test server.
+	testMux *http.ServeMux
+
+	// testClient is the dbapi client used by the functions which fetch
+	// accounts and transactions.
+	testClient *dbapi.Client
+
+	// testServer is a test HTTP server used to provide mock API responses.
+	testServer *httptest.Server
+)
+
+func analyticsTransactions() dbapi.Transactions {
+	return dbapi.Transactions{
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-35.56"), CounterPartyName: "Netto", BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-52.22"), CounterPartyName: "Lidl", BookingDate: mustParseDate("2016-10-24")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", BookingDate: mustParseDate("2016-10-21")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("2500"), CounterPartyName: "ACME GmbH", Usage: "Gehalt", BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-08-01")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-20.01"), CounterPartyName: "NETTO ", BookingDate: mustParseDate("2016-08-30")},
+	}
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	c, err := NewCategorizer(DefaultRules()...)
+	ok(t, err)
+	a := &Analyzer{Categorizer: c}
+
+	r := a.Analyze(analyticsTransactions())
+
+	equals(t, mustParseDate("2016-08-01"), r.From)
+	equals(t, mustParseDate("2016-10-27"), r.To)
+	equals(t, Summary{Income: eur("2600"), Spending: eur("1607.79"), Net: eur("992.21"), Count: 7}, r.Total)
+
+	// Months, including the one without transactions.
+	equals(t, 3, len(r.Months))
+	aug, sep, oct := r.Months[0], r.Months[1], r.Months[2]
+	equals(t, time.August, aug.Month)
+	equals(t, Summary{Income: eur("50"), Spending: eur("20.01"), Net: eur("29.99"), Count: 2}, aug.Summary)
+	equals(t, dbapi.Money{}, aug.NetDelta)
+	equals(t, time.September, sep.Month)
+	equals(t, 0, sep.Count)
+	equals(t, eur("-29.99"), sep.NetDelta)
+	equals(t, eur("25"), sep.AverageIncome)
+	equals(t, eur("10.01"), sep.AverageSpending)
+	equals(t, time.October, oct.Month)
+	equals(t, Summary{Income: eur("2550"), Spending: eur("1587.78"), Net: eur("962.22"), Count: 5}, oct.Summary)
+	equals(t, eur("2550"), oct.IncomeDelta)
+	equals(t, eur("1587.78"), oct.SpendingDelta)
+	equals(t, eur("866.67"), oct.AverageIncome)
+	equals(t, eur("535.93"), oct.AverageSpending)
+
+	// Weeks from the Monday of the first week up to the last transaction.
+	equals(t, 13, len(r.Weeks))
+	equals(t, mustParseDate("2016-08-01"), r.Weeks[0].Start)
+	equals(t, 31, r.Weeks[0].Week)
+	equals(t, mustParseDate("2016-10-24"), r.Weeks[12].Start)
+	equals(t, 2, r.Weeks[12].Count)
+
+	// Categories by spending.
+	equals(t, Housing, r.Categories[0].Category)
+	equals(t, eur("1500"), r.Categories[0].Spending)
+	equals(t, Groceries, r.Categories[1].Category)
+	equals(t, eur("107.79"), r.Categories[1].Spending)
+	assert(t, r.Categories[0].Share > 0.93 && r.Categories[0].Share < 0.94, "unexpected share %v", r.Categories[0].Share)
+
+	// Counterparties by spending, grouped ignoring case and whitespace.
+	equals(t, "Schwäbisch Hall", r.CounterParties[0].CounterParty)
+	equals(t, "Netto", r.CounterParties[1].CounterParty)
+	equals(t, Summary{Spending: eur("55.57"), Net: eur("-55.57"), Count: 2}, r.CounterParties[1].Summary)
+
+	// Accounts by IBAN.
+	equals(t, 2, len(r.Accounts))
+	equals(t, dbapi.IBAN("DE10000000000000000455"), r.Accounts[1].IBAN)
+	equals(t, eur("100"), r.Accounts[1].Net)
+}
+
+func TestAnalyzer_Exclude(t *testing.T) {
+	a := &Analyzer{
+		Exclude:      func(tx dbapi.Transaction) bool { return tx.Usage == "Sparen Samuel" },
+		CounterParty: func(tx dbapi.Transaction) string { return "all" },
+	}
+
+	r := a.Analyze(analyticsTransactions())
+	equals(t, 5, r.Total.Count)
+	equals(t, mustParseDate("2016-08-30"), r.From)
+	equals(t, 0, len(r.Categories))
+	equals(t, 1, len(r.CounterParties))
+	equals(t, "all", r.CounterParties[0].CounterParty)
+}
+
+func TestAnalyzer_Analyze_Undated(t *testing.T) {
+	txs := dbapi.Transactions{
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-20"), BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-5")},
+	}
+
+	r := new(Analyzer).Analyze(txs)
+	equals(t, 2, r.Total.Count)
+	equals(t, eur("25"), r.Total.Spending)
+	equals(t, 1, r.Undated.Count)
+	equals(t, eur("5"), r.Undated.Spending)
+	equals(t, mustParseDate("2016-10-27"), r.From)
+	equals(t, mustParseDate("2016-10-27"), r.To)
+	equals(t, 1, len(r.Months))
+	equals(t, eur("20"), r.Months[0].Spending)
+	equals(t, 1, len(r.Weeks))
+	equals(t, 1, r.Weeks[0].Count)
+
+	r = new(Analyzer).Analyze(txs[1:])
+	equals(t, 1, r.Total.Count)
+	equals(t, dbapi.Date{}, r.From)
+	equals(t, 0, len(r.Months))
+	equals(t, 0, len(r.Weeks))
+}
+
+func TestAnalyzer_Analyze_Empty(t *testing.T) {
+	r := new(Analyzer).Analyze(nil)
+	equals(t, &Report{}, r)
+}
+
+func TestAverage(t *testing.T) {
+	equals(t, eur("0.34"), average(eur("1.01"), 3))
+	equals(t, eur("0.67"), average(eur("2.01"), 3))
+	equals(t, eur("-0.67"), average(eur("-2.01"), 3))
+	equals(t, eur("0.01"), average(eur("0.01"), 2))
+	equals(t, eur("-0.01"), average(eur("-0.01"), 2))
+}
+
+// setup sets up a test HTTP server along with a dbapi.Client that is configured
+// to talk to that test server. Tests should register handlers on mux which
+// provide mock responses for the API endpoints being used.
+func setup() {
+	testMux = http.NewServeMux()
+	testServer = httptest.NewServer(testMux)
+
+	testClient, _ = dbapi.NewClient(
+		dbapi.SetToken("1234567890abcdefghijklmnopqrstuvwxyz"),
+		dbapi.SetURL(testServer.URL),
+	)
+}
+
+// teardown closes the test HTTP server.
+func teardown() {
+	testServer.Close()
+}
+
+// eur parses the amount as Money in the currency EUR. It panics if the amount
+// is invalid.
+func eur(amount string) dbapi.Money {
+	m, err := dbapi.ParseMoney(amount, dbapi.EUR)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// mustParseDate parses the date in ISO 8601 format. It panics if the date is
+// invalid.
+func mustParseDate(s string) dbapi.Date {
+	d, err := dbapi.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// counterParties returns the counterparty names of the transactions.
+func counterParties(t dbapi.Transactions) []string {
+	names := make([]string, len(t))
+	for i, tx := range t {
+		names[i] = tx.CounterPartyName
+	}
+	return names
+}
+
+// assert fails the test if the condition is false.
+func assert(tb testing.TB, condition bool, msg string, v ...interface{}) {
+	if !condition {
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("\033[31m%s:%d: "+msg+"\033[39m\n\n", append([]interface{}{filepath.Base(file), line}, v...)...)
+		tb.FailNow()
+	}
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	if err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("\033[31m%s:%d: unexpected error: %s\033[39m\n\n", filepath.Base(file), line, err.Error())
+		tb.FailNow()
+	}
+}
+
+// equals fails the test if exp is not equal to act.
+func equals(tb testing.TB, exp, act interface{}) {
+	if !reflect.DeepEqual(exp, act) {
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("\033[31m%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\033[39m\n\n", filepath.Base(file), line, exp, act)
+		tb.FailNow()
+	}
+}
@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+// A BalancePoint is the end-of-day balance of an account on a day. It is also
+// used for known balances (snapshots), like the balance of an account which
+// was stored on an earlier day.
+type BalancePoint struct {
+	Date    dbapi.Date
+	Balance dbapi.Money
+}
+
+// BalanceIssueType is the type of a BalanceIssue.
+type BalanceIssueType string
+
+// Available types of balance issues.
+const (
+	// BalanceGap means there are no transactions for an unusually long
+	// period, so transactions might be missing and the balances before the
+	// gap might be wrong.
+	BalanceGap BalanceIssueType = "gap"
+	// SnapshotMismatch means the reconstructed balance contradicts a known
+	// balance.
+	SnapshotMismatch BalanceIssueType = "snapshot_mismatch"
+)
+
+// A BalanceIssue is an inconsistency found while reconstructing balances.
+type BalanceIssue struct {
+	Type BalanceIssueType
+	// From and To are the first and last day of a gap or the day of a
+	// snapshot.
+	From, To dbapi.Date
+	// Expected is the balance of a snapshot, Actual the reconstructed balance
+	// on that day. Both are zero for gaps.
+	Expected dbapi.Money
+	Actual   dbapi.Money
+}
+
+// A BalanceHistory is the reconstructed daily balance of an account.
+type BalanceHistory struct {
+	Account dbapi.IBAN
+	// Points are the end-of-day balances of all days in chronological order.
+	// The first point is the day before the first transaction, the last point
+	// is the day the history was reconstructed as of. Without transactions,
+	// there is only the last point.
+	Points []BalancePoint
+	// Issues are the inconsistencies found in chronological order.
+	Issues []BalanceIssue
+}
+
+// balanceGapDays is the number of consecutive days without transactions which
+// is considered a gap.
+const balanceGapDays = 45
+
+// ReconstructBalances rebuilds the daily end-of-day balances of the account by
+// walking its transactions backwards from its balance, which must be the
+// balance at the end of the day asOf. Transactions of other accounts,
+// transactions booked after asOf and transactions without booking date are
+// ignored.
+//
+// Periods of more than 45 days without transactions are reported as gaps. The
+// reconstructed balances are compared with the snapshots, which are known
+// balances of the account; contradictions are reported as mismatches.
+// Snapshots outside of the reconstructed period can't be checked and are
+// ignored.
+func ReconstructBalances(acc dbapi.Account, t dbapi.Transactions, asOf dbapi.Date, snapshots ...BalancePoint) *BalanceHistory {
+	h := &BalanceHistory{Account: acc.Iban}
+
+	daily := make(map[dbapi.Date]dbapi.Money)
+	start := asOf
+	for _, tx := range t {
+		if tx.OriginIBAN != acc.Iban || tx.BookingDate.IsZero() || tx.BookingDate.After(asOf) {
+			continue
+		}
+		daily[tx.BookingDate] = daily[tx.BookingDate].Add(tx.Amount)
+		if d := tx.BookingDate.AddDays(-1); d.Before(start) {
+			start = d
+		}
+	}
+
+	// Walk backwards from the known balance: the balance at the end of the
+	// previous day is the balance of a day minus its transactions.
+	h.Points = make([]BalancePoint, asOf.DaysSince(start)+1)
+	balance := acc.Balance
+	for i := len(h.Points) - 1; i >= 0; i-- {
+		d := start.AddDays(i)
+		h.Points[i] = BalancePoint{Date: d, Balance: balance}
+		balance = balance.Sub(daily[d])
+	}
+
+	// Report gaps between transactions and between the last transaction and
+	// asOf.
+	last := start
+	for i := 1; i < len(h.Points); i++ {
+		d := h.Points[i].Date
+		if _, ok := daily[d]; !ok && d != asOf {
+			continue
+		}
+		if gap := d.DaysSince(last) - 1; gap > balanceGapDays {
+			h.Issues = append(h.Issues, BalanceIssue{Type: BalanceGap, From: last.AddDays(1), To: d.AddDays(-1)})
+		}
+		last = d
+	}
+
+	for _, s := range snapshots {
+		actual, ok := h.At(s.Date)
+		if !ok || actual.Equal(s.Balance) {
+			continue
+		}
+		h.Issues = append(h.Issues, BalanceIssue{Type: SnapshotMismatch, From: s.Date, To: s.Date, Expected: s.Balance, Actual: actual})
+	}
+	sort.SliceStable(h.Issues, func(i, j int) bool {
+		return h.Issues[i].From.Before(h.Issues[j].From)
+	})
+	return h
+}
+
+// BalanceHistories reconstructs the balance histories of all accounts (see
+// ReconstructBalances). The balances of the accounts must be their balances
+// at the end of the day asOf.
+func BalanceHistories(accounts dbapi.Accounts, t dbapi.Transactions, asOf dbapi.Date) map[dbapi.IBAN]*BalanceHistory {
+	histories := make(map[dbapi.IBAN]*BalanceHistory, len(accounts))
+	for _, acc := range accounts {
+		histories[acc.Iban] = ReconstructBalances(acc, t, asOf)
+	}
+	return histories
+}
+
+// At returns the end-of-day balance on the date. The second return value
+// reports whether the date is within the reconstructed period.
+func (h *BalanceHistory) At(d dbapi.Date) (dbapi.Money, bool) {
+	if len(h.Points) == 0 {
+		return dbapi.Money{}, false
+	}
+	i := d.DaysSince(h.Points[0].Date)
+	if i < 0 || i >= len(h.Points) {
+		return dbapi.Money{}, false
+	}
+	return h.Points[i].Balance, true
+}
@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+func TestReconstructBalances(t *testing.T) {
+	acc := dbapi.Account{Iban: "DE10000000000000000454", Balance: eur("1000")}
+	txs := dbapi.Transactions{
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-35.56"), BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-52.22"), BookingDate: mustParseDate("2016-10-24")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-12.22"), BookingDate: mustParseDate("2016-10-24")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("2500"), BookingDate: mustParseDate("2016-10-21")},
+		// Transactions of other accounts, after asOf and without booking date
+		// are ignored.
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-99"), BookingDate: mustParseDate("2016-10-29")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-7")},
+	}
+	asOf := mustParseDate("2016-10-28")
+
+	h := ReconstructBalances(acc, txs, asOf)
+
+	equals(t, dbapi.IBAN("DE10000000000000000454"), h.Account)
+	equals(t, 9, len(h.Points))
+	equals(t, BalancePoint{Date: mustParseDate("2016-10-20"), Balance: eur("-1400")}, h.Points[0])
+	equals(t, BalancePoint{Date: asOf, Balance: eur("1000")}, h.Points[8])
+	equals(t, 0, len(h.Issues))
+
+	mockData := []struct {
+		date  string
+		exp   dbapi.Money
+		found bool
+	}{
+		{"2016-10-19", dbapi.Money{}, false},
+		{"2016-10-20", eur("-1400"), true},
+		{"2016-10-21", eur("1100"), true},
+		{"2016-10-23", eur("1100"), true},
+		{"2016-10-24", eur("1035.56"), true},
+		{"2016-10-27", eur("1000"), true},
+		{"2016-10-28", eur("1000"), true},
+		{"2016-10-29", dbapi.Money{}, false},
+	}
+	for _, tt := range mockData {
+		act, found := h.At(mustParseDate(tt.date))
+		equals(t, tt.found, found)
+		equals(t, tt.exp, act)
+	}
+}
+
+func TestReconstructBalances_Issues(t *testing.T) {
+	acc := dbapi.Account{Iban: "DE10000000000000000454", Balance: eur("100")}
+	txs := dbapi.Transactions{
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-50"), BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-50"), BookingDate: mustParseDate("2016-07-01")},
+	}
+	asOf := mustParseDate("2016-10-10")
+	snapshots := []BalancePoint{
+		{Date: mustParseDate("2016-09-01"), Balance: eur("150")},
+		{Date: mustParseDate("2016-10-05"), Balance: eur("80")},
+		// Outside of the reconstructed period.
+		{Date: mustParseDate("2016-01-01"), Balance: eur("0")},
+	}
+
+	h := ReconstructBalances(acc, txs, asOf, snapshots...)
+
+	equals(t, []BalanceIssue{
+		{Type: BalanceGap, From: mustParseDate("2016-07-02"), To: mustParseDate("2016-09-30")},
+		{Type: SnapshotMismatch, From: mustParseDate("2016-10-05"), To: mustParseDate("2016-10-05"), Expected: eur("80"), Actual: eur("100")},
+	}, h.Issues)
+}
+
+func TestBalanceHistories(t *testing.T) {
+	accounts := dbapi.Accounts{
+		{Iban: "DE10000000000000000454", Balance: eur("250")},
+		{Iban: "DE10000000000000000455", Balance: eur("100")},
+	}
+	txs := dbapi.Transactions{
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), BookingDate: mustParseDate("2016-10-01")},
+	}
+
+	h := BalanceHistories(accounts, txs, mustParseDate("2016-10-02"))
+	equals(t, 2, len(h))
+	equals(t, 1, len(h["DE10000000000000000454"].Points))
+	b, found := h["DE10000000000000000455"].At(mustParseDate("2016-09-30"))
+	assert(t, found, "expected balance to be found")
+	equals(t, eur("50"), b)
+}
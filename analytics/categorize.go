@@ -0,0 +1,374 @@
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lukasmalkmus/dbapi"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrInvalidRule is raised when a categorization rule is invalid (e.g. without
+// category or with a malformed regular expression).
+var ErrInvalidRule = errors.New("Invalid categorization rule")
+
+// A Category is the spending or income category of a transaction. The empty
+// category means the transaction isn't categorized.
+type Category string
+
+// Categories used by the default rules.
+const (
+	Uncategorized Category = ""
+	Groceries     Category = "groceries"
+	Fuel          Category = "fuel"
+	Housing       Category = "housing"
+	Shopping      Category = "shopping"
+	Transport     Category = "transport"
+	Insurance     Category = "insurance"
+	Telecom       Category = "telecom"
+	Cash          Category = "cash"
+	Salary        Category = "salary"
+	Savings       Category = "savings"
+)
+
+func (c Category) String() string {
+	return string(c)
+}
+
+// Direction restricts a rule to credits or debits.
+type Direction string
+
+// Available directions.
+const (
+	// Credit matches transactions by which the user gained money.
+	Credit Direction = "credit"
+	// Debit matches transactions by which the user lost money.
+	Debit Direction = "debit"
+)
+
+// A Rule assigns a category to the transactions which match all of its
+// conditions. Conditions which are empty are ignored, but at least one must be
+// set. CounterParty and Usage are regular expressions which are matched
+// ignoring case.
+//
+// If several rules match a transaction, the rule with the highest priority
+// wins. Among rules of equal priority the most specific one, which has the
+// most conditions, wins, and among those the one which comes first.
+type Rule struct {
+	Name     string   `json:"name"`
+	Category Category `json:"category"`
+	Priority int      `json:"priority,omitempty"`
+
+	CounterParty     string       `json:"counterParty,omitempty"`
+	CounterPartyIBAN dbapi.IBAN   `json:"counterPartyIban,omitempty"`
+	Account          dbapi.IBAN   `json:"account,omitempty"`
+	Usage            string       `json:"usage,omitempty"`
+	MinAmount        *dbapi.Money `json:"minAmount,omitempty"`
+	MaxAmount        *dbapi.Money `json:"maxAmount,omitempty"`
+	Direction        Direction    `json:"direction,omitempty"`
+
+	counterParty *regexp.Regexp
+	usage        *regexp.Regexp
+}
+
+// compile validates the rule and compiles its regular expressions.
+func (r *Rule) compile() error {
+	if r.Category == Uncategorized {
+		return fmt.Errorf("%w %q: no category", ErrInvalidRule, r.Name)
+	}
+	if r.specificity() == 0 {
+		return fmt.Errorf("%w %q: no condition", ErrInvalidRule, r.Name)
+	}
+	if r.Direction != "" && r.Direction != Credit && r.Direction != Debit {
+		return fmt.Errorf("%w %q: unknown direction %q", ErrInvalidRule, r.Name, r.Direction)
+	}
+	var err error
+	if r.counterParty, err = compileRulePattern(r.CounterParty); err != nil {
+		return fmt.Errorf("%w %q: counterParty: %v", ErrInvalidRule, r.Name, err)
+	}
+	if r.usage, err = compileRulePattern(r.Usage); err != nil {
+		return fmt.Errorf("%w %q: usage: %v", ErrInvalidRule, r.Name, err)
+	}
+	r.CounterPartyIBAN = r.CounterPartyIBAN.Normalize()
+	r.Account = r.Account.Normalize()
+	return nil
+}
+
+// compileRulePattern compiles the pattern to match ignoring case. The empty
+// pattern compiles to nil.
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// specificity returns the number of conditions of the rule.
+func (r *Rule) specificity() int {
+	n := 0
+	for _, set := range []bool{
+		r.CounterParty != "",
+		r.CounterPartyIBAN != "",
+		r.Account != "",
+		r.Usage != "",
+		r.MinAmount != nil,
+		r.MaxAmount != nil,
+		r.Direction != "",
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// match reports whether the transaction matches all conditions of the rule and
+// returns a description of each condition.
+func (r *Rule) match(t dbapi.Transaction) ([]string, bool) {
+	var reasons []string
+	if r.counterParty != nil {
+		if !r.counterParty.MatchString(t.CounterPartyName) {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("counterparty %q matches %q", t.CounterPartyName, r.CounterParty))
+	}
+	if r.CounterPartyIBAN != "" {
+		if t.CounterPartyIBAN != r.CounterPartyIBAN {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("counterparty IBAN is %s", r.CounterPartyIBAN))
+	}
+	if r.Account != "" {
+		if t.OriginIBAN != r.Account {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("account is %s", r.Account))
+	}
+	if r.usage != nil {
+		if !r.usage.MatchString(t.Usage) {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("usage %q matches %q", t.Usage, r.Usage))
+	}
+	if r.MinAmount != nil {
+		if t.Amount.Cmp(*r.MinAmount) < 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("amount %s is at least %s", t.Amount, *r.MinAmount))
+	}
+	if r.MaxAmount != nil {
+		if t.Amount.Cmp(*r.MaxAmount) > 0 {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("amount %s is at most %s", t.Amount, *r.MaxAmount))
+	}
+	switch {
+	case r.Direction == Credit && !t.IsCredit(), r.Direction == Debit && !t.IsDebit():
+		return nil, false
+	case r.Direction != "":
+		reasons = append(reasons, fmt.Sprintf("transaction is a %s", r.Direction))
+	}
+	return reasons, true
+}
+
+// A RuleMatch is a rule which matched a transaction.
+type RuleMatch struct {
+	Rule Rule
+	// Reasons describe the conditions of the rule which the transaction
+	// fulfilled.
+	Reasons []string
+}
+
+// An Explanation tells why a transaction was put into its category.
+type Explanation struct {
+	// Category is the category of the transaction.
+	Category Category
+	// Matches are all rules which matched the transaction, the winning rule
+	// first. It is empty if the transaction is uncategorized.
+	Matches []RuleMatch
+	// Conflicts are the matching rules which assign a different category than
+	// the winning rule and are ranked equally, so only their order decided.
+	Conflicts []RuleMatch
+}
+
+// String describes the explanation in a single line.
+func (e Explanation) String() string {
+	if len(e.Matches) == 0 {
+		return "uncategorized: no rule matched"
+	}
+	m := e.Matches[0]
+	s := fmt.Sprintf("%s by rule %q: %s", e.Category, m.Rule.Name, strings.Join(m.Reasons, ", "))
+	for _, c := range e.Conflicts {
+		s += fmt.Sprintf(" (conflicts with rule %q for %s)", c.Rule.Name, c.Rule.Category)
+	}
+	return s
+}
+
+// A Categorizer assigns categories to transactions by rules. It is safe for
+// concurrent use.
+type Categorizer struct {
+	rules []Rule
+}
+
+// NewCategorizer returns a categorizer which uses the rules. An error wrapping
+// ErrInvalidRule is returned if a rule is invalid. Use DefaultRules to start
+// with the built-in rules:
+//
+//	c, err := analytics.NewCategorizer(append(analytics.DefaultRules(), myRules...)...)
+func NewCategorizer(rules ...Rule) (*Categorizer, error) {
+	c := &Categorizer{rules: make([]Rule, len(rules))}
+	copy(c.rules, rules)
+	for i := range c.rules {
+		if err := c.rules[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+	// Rank the rules once, so the first matching rule wins. The sort is stable
+	// to keep the order of equally ranked rules.
+	sort.SliceStable(c.rules, func(i, j int) bool {
+		return c.rules[i].rank(&c.rules[j]) > 0
+	})
+	return c, nil
+}
+
+// rank compares the rules by priority and then by specificity and returns -1,
+// 0 or +1 if r ranks lower, equal or higher than o.
+func (r *Rule) rank(o *Rule) int {
+	a, b := [2]int{r.Priority, r.specificity()}, [2]int{o.Priority, o.specificity()}
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Rules returns the rules of the categorizer in the order they are evaluated.
+func (c *Categorizer) Rules() []Rule {
+	rules := make([]Rule, len(c.rules))
+	copy(rules, c.rules)
+	return rules
+}
+
+// Categorize returns the category of the transaction. It is Uncategorized if no
+// rule matches.
+func (c *Categorizer) Categorize(t dbapi.Transaction) Category {
+	for i := range c.rules {
+		if _, ok := c.rules[i].match(t); ok {
+			return c.rules[i].Category
+		}
+	}
+	return Uncategorized
+}
+
+// Explain categorizes the transaction and tells which rules matched.
+func (c *Categorizer) Explain(t dbapi.Transaction) Explanation {
+	var e Explanation
+	for i := range c.rules {
+		r := &c.rules[i]
+		reasons, ok := r.match(t)
+		if !ok {
+			continue
+		}
+		m := RuleMatch{Rule: *r, Reasons: reasons}
+		if len(e.Matches) == 0 {
+			e.Category = r.Category
+		} else if w := &e.Matches[0].Rule; r.Category != w.Category && r.rank(w) == 0 {
+			e.Conflicts = append(e.Conflicts, m)
+		}
+		e.Matches = append(e.Matches, m)
+	}
+	return e
+}
+
+// Group categorizes the transactions and groups them by category. The order of
+// the transactions is preserved within each group.
+func (c *Categorizer) Group(t dbapi.Transactions) map[Category]dbapi.Transactions {
+	groups := make(map[Category]dbapi.Transactions)
+	for _, tx := range t {
+		cat := c.Categorize(tx)
+		groups[cat] = append(groups[cat], tx)
+	}
+	return groups
+}
+
+// LoadRules reads a list of rules in YAML or JSON format, like:
+//
+//	# rules.yaml
+//	- name: bakery
+//	  category: groceries
+//	  counterParty: "bäckerei|backhaus"
+//	  direction: debit
+//	- name: rent
+//	  category: housing
+//	  priority: 20
+//	  counterPartyIban: DE89370400440532013000
+//
+// The rules are only validated by NewCategorizer.
+func LoadRules(r io.Reader) ([]Rule, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is a subset of YAML, so both formats are decoded as YAML. The result
+	// is converted to JSON, so the fields are decoded by the same code (e.g.
+	// Money.UnmarshalJSON) no matter the format.
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	if b, err = json.Marshal(yamlToJSON(v)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return rules, nil
+}
+
+// yamlToJSON converts the maps decoded by the YAML package, which have keys of
+// type interface{}, into maps which can be encoded as JSON.
+func yamlToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = yamlToJSON(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = yamlToJSON(e)
+		}
+	}
+	return v
+}
+
+// DefaultRules returns the built-in rules, which categorize the transactions of
+// common German merchants and some typical usages. Merchant rules have priority
+// 10, rules on the usage have priority 0, so merchants win.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "supermarkets", Category: Groceries, Priority: 10, CounterParty: `\b(netto|lidl|aldi|rewe|edeka|penny|kaufland|alnatura|tegut|norma)\b`},
+		{Name: "petrol stations", Category: Fuel, Priority: 10, CounterParty: `\b(jet|aral|shell|esso|totalenergies|total|agip|tankstelle)\b`},
+		{Name: "building societies", Category: Housing, Priority: 10, CounterParty: `schwäbisch hall|bausparkasse|\blbs\b|wüstenrot`},
+		{Name: "retailers", Category: Shopping, Priority: 10, CounterParty: `\b(amazon|zalando|toys ?r ?us|media ?markt|saturn|otto|ikea)\b`},
+		{Name: "public transport", Category: Transport, Priority: 10, CounterParty: `\b(db vertrieb|deutsche bahn|bvg|mvg|hvv|rmv)\b`},
+		{Name: "insurers", Category: Insurance, Priority: 10, CounterParty: `\b(allianz|huk|ergo|axa|debeka|generali)\b`},
+		{Name: "telecommunication", Category: Telecom, Priority: 10, CounterParty: `\b(telekom|vodafone|o2|telefonica|1&1)\b`},
+		{Name: "rent", Category: Housing, Usage: `\b(miete|kaltmiete|nebenkosten)\b`, Direction: Debit},
+		{Name: "cash withdrawals", Category: Cash, Usage: `\b(bargeldauszahlung|gaa|geldautomat)\b`, Direction: Debit},
+		{Name: "salary", Category: Salary, Usage: `\b(lohn|gehalt|bezüge)\b`, Direction: Credit},
+		{Name: "savings", Category: Savings, Usage: `\bsparen\b`},
+	}
+}
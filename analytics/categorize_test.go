@@ -0,0 +1,185 @@
+package analytics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+func TestCategorizer_DefaultRules(t *testing.T) {
+	c, err := NewCategorizer(DefaultRules()...)
+	ok(t, err)
+
+	mockData := []struct {
+		tx  dbapi.Transaction
+		exp Category
+	}{
+		{dbapi.Transaction{Amount: eur("-35.56"), CounterPartyName: "Netto", Usage: "POS MIT PIN. Einkauf"}, Groceries},
+		{dbapi.Transaction{Amount: eur("-52.22"), CounterPartyName: "Lidl", Usage: "POS MIT PIN. Einkauf"}, Groceries},
+		{dbapi.Transaction{Amount: eur("-25.95"), CounterPartyName: "Alnatura Frankfurt", Usage: "POS MIT PIN. Einkauf"}, Groceries},
+		{dbapi.Transaction{Amount: eur("-96.16"), CounterPartyName: "JET", Usage: "POS MIT PIN. Die Tanke Ihrer Wahl"}, Fuel},
+		{dbapi.Transaction{Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", Usage: "Ref. 58974-8765889"}, Housing},
+		{dbapi.Transaction{Amount: eur("-38.98"), CounterPartyName: "Toys R Us", Usage: "Rechnung"}, Shopping},
+		{dbapi.Transaction{Amount: eur("50"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel"}, Savings},
+		{dbapi.Transaction{Amount: eur("2500"), CounterPartyName: "ACME GmbH", Usage: "Gehalt Oktober"}, Salary},
+		{dbapi.Transaction{Amount: eur("-800"), CounterPartyName: "Hausverwaltung", Usage: "Miete Oktober"}, Housing},
+		{dbapi.Transaction{Amount: eur("800"), CounterPartyName: "Hausverwaltung", Usage: "Miete Oktober"}, Uncategorized},
+		{dbapi.Transaction{Amount: eur("-10"), CounterPartyName: "Jetset Reisen", Usage: "Anzahlung"}, Uncategorized},
+	}
+
+	for _, tt := range mockData {
+		equals(t, tt.exp, c.Categorize(tt.tx))
+	}
+}
+
+func TestCategorizer_Priority(t *testing.T) {
+	groceries := Rule{Name: "netto", Category: Groceries, CounterParty: "netto"}
+	fuel := Rule{Name: "netto fuel", Category: Fuel, CounterParty: "netto", Usage: "tank"}
+	override := Rule{Name: "override", Category: Shopping, Priority: 5, Usage: "tank"}
+
+	tx := dbapi.Transaction{Amount: eur("-40"), CounterPartyName: "Netto", Usage: "Tankstelle"}
+
+	// The more specific rule wins regardless of the order.
+	c, err := NewCategorizer(groceries, fuel)
+	ok(t, err)
+	equals(t, Fuel, c.Categorize(tx))
+
+	// The rule with the higher priority wins regardless of specificity.
+	c, err = NewCategorizer(groceries, fuel, override)
+	ok(t, err)
+	equals(t, Shopping, c.Categorize(tx))
+	equals(t, []string{"override", "netto fuel", "netto"}, ruleNames(c.Rules()))
+}
+
+func TestCategorizer_Explain(t *testing.T) {
+	c, err := NewCategorizer(
+		Rule{Name: "netto", Category: Groceries, CounterParty: "netto"},
+		Rule{Name: "discounter", Category: Shopping, CounterParty: "discount"},
+		Rule{Name: "debits", Category: "other", Direction: Debit},
+	)
+	ok(t, err)
+
+	e := c.Explain(dbapi.Transaction{Amount: eur("-35.56"), CounterPartyName: "Netto Marken-Discount"})
+	equals(t, Groceries, e.Category)
+	equals(t, []string{"netto", "discounter", "debits"}, matchNames(e.Matches))
+	equals(t, []string{`counterparty "Netto Marken-Discount" matches "netto"`}, e.Matches[0].Reasons)
+	equals(t, []string{"discounter", "debits"}, matchNames(e.Conflicts))
+	assert(t, strings.HasPrefix(e.String(), `groceries by rule "netto": counterparty`), "unexpected explanation %q", e.String())
+
+	e = c.Explain(dbapi.Transaction{Amount: eur("10"), CounterPartyName: "Claudia Klar"})
+	equals(t, Uncategorized, e.Category)
+	equals(t, 0, len(e.Matches))
+	equals(t, "uncategorized: no rule matched", e.String())
+}
+
+func TestCategorizer_Conditions(t *testing.T) {
+	min, max := eur("-100"), eur("-10")
+	c, err := NewCategorizer(Rule{
+		Name:             "all",
+		Category:         Housing,
+		CounterPartyIBAN: "de89 3704 0044 0532 0130 00",
+		Account:          "DE10000000000000000454",
+		MinAmount:        &min,
+		MaxAmount:        &max,
+		Direction:        Debit,
+	})
+	ok(t, err)
+
+	tx := dbapi.Transaction{OriginIBAN: "DE10000000000000000454", CounterPartyIBAN: "DE89370400440532013000", Amount: eur("-50")}
+	equals(t, Housing, c.Categorize(tx))
+	equals(t, 5, len(c.Explain(tx).Matches[0].Reasons))
+
+	for _, change := range []func(*dbapi.Transaction){
+		func(tx *dbapi.Transaction) { tx.OriginIBAN = "DE10000000000000000455" },
+		func(tx *dbapi.Transaction) { tx.CounterPartyIBAN = "" },
+		func(tx *dbapi.Transaction) { tx.Amount = eur("-100.01") },
+		func(tx *dbapi.Transaction) { tx.Amount = eur("-9.99") },
+	} {
+		other := tx
+		change(&other)
+		equals(t, Uncategorized, c.Categorize(other))
+	}
+}
+
+func TestNewCategorizer_InvalidRule(t *testing.T) {
+	for _, r := range []Rule{
+		{Name: "no category", CounterParty: "netto"},
+		{Name: "no condition", Category: Groceries},
+		{Name: "bad regexp", Category: Groceries, CounterParty: "("},
+		{Name: "bad direction", Category: Groceries, Direction: "sideways"},
+	} {
+		_, err := NewCategorizer(r)
+		assert(t, errors.Is(err, ErrInvalidRule), "expected ErrInvalidRule for %q, got %v", r.Name, err)
+	}
+}
+
+func TestCategorizer_Group(t *testing.T) {
+	c, err := NewCategorizer(DefaultRules()...)
+	ok(t, err)
+
+	groups := c.Group(dbapi.Transactions{
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-35.56"), CounterPartyName: "Netto", BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", BookingDate: mustParseDate("2016-10-21")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-25.95"), CounterPartyName: "Alnatura Frankfurt", BookingDate: mustParseDate("2016-10-17")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-38.98"), CounterPartyName: "Toys R Us", BookingDate: mustParseDate("2016-10-17")},
+	})
+	equals(t, []string{"Netto", "Alnatura Frankfurt"}, counterParties(groups[Groceries]))
+	equals(t, []string{"Schwäbisch Hall"}, counterParties(groups[Housing]))
+	equals(t, []string{"Claudia Klar"}, counterParties(groups[Uncategorized]))
+}
+
+func TestLoadRules(t *testing.T) {
+	minAmount := eur("-50.5")
+	exp := []Rule{
+		{Name: "bakery", Category: Groceries, CounterParty: "bäckerei|backhaus", Direction: Debit},
+		{Name: "rent", Category: Housing, Priority: 20, CounterPartyIBAN: "DE89370400440532013000", MinAmount: &minAmount},
+	}
+
+	yamlRules := `
+- name: bakery
+  category: groceries
+  counterParty: "bäckerei|backhaus"
+  direction: debit
+- name: rent
+  category: housing
+  priority: 20
+  counterPartyIban: DE89370400440532013000
+  minAmount: -50.50
+`
+	act, err := LoadRules(strings.NewReader(yamlRules))
+	ok(t, err)
+	equals(t, exp, act)
+
+	jsonRules := `[
+		{"name": "bakery", "category": "groceries", "counterParty": "bäckerei|backhaus", "direction": "debit"},
+		{"name": "rent", "category": "housing", "priority": 20, "counterPartyIban": "DE89370400440532013000", "minAmount": "-50.50"}
+	]`
+	act, err = LoadRules(strings.NewReader(jsonRules))
+	ok(t, err)
+	equals(t, exp, act)
+
+	_, err = NewCategorizer(act...)
+	ok(t, err)
+
+	_, err = LoadRules(strings.NewReader(`name: not a list`))
+	assert(t, errors.Is(err, ErrInvalidRule), "expected ErrInvalidRule, got %v", err)
+}
+
+func ruleNames(rules []Rule) []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func matchNames(matches []RuleMatch) []string {
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Rule.Name
+	}
+	return names
+}
@@ -0,0 +1,29 @@
+/*
+Package analytics provides budgeting features on top of the accounts and
+transactions fetched with package dbapi: rule-based categorization, income and
+spending reports, detection of recurring payments, cash-flow forecasts,
+reconstruction of historical balances, matching of transfers between the
+user's own accounts and normalization of counterparty names.
+
+It is kept apart from package dbapi, so the API client stays small and free
+of dependencies.
+
+	accounts, _, err := api.Accounts.GetAll()
+	if err != nil {
+	    log.Fatalln(err)
+	}
+	transactions, _, err := api.Transactions.GetAll()
+	if err != nil {
+	    log.Fatalln(err)
+	}
+
+	categorizer, err := analytics.NewCategorizer(analytics.DefaultRules()...)
+	if err != nil {
+	    log.Fatalln(err)
+	}
+	transfers := analytics.MatchTransfers(*accounts, *transactions, 3)
+	a := &analytics.Analyzer{Categorizer: categorizer}
+	report := a.Analyze(transfers.Exclude(*transactions))
+	fmt.Printf("%v", report.Months)
+*/
+package analytics
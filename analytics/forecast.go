@@ -0,0 +1,202 @@
+package analytics
+
+import (
+	"context"
+	"math"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+// A ForecastPoint is the projected end-of-day balance of an account on a day.
+type ForecastPoint struct {
+	Date dbapi.Date
+	// Balance is the expected balance. Low and High are the bounds of the
+	// confidence band around it.
+	Balance dbapi.Money
+	Low     dbapi.Money
+	High    dbapi.Money
+}
+
+// An OverdraftWarning predicts that the balance of an account becomes negative.
+type OverdraftWarning struct {
+	// Date is the first day on which the balance may be negative.
+	Date dbapi.Date
+	// Balance is the lower bound of the projected balance on that day.
+	Balance dbapi.Money
+	// Likely reports whether the expected balance becomes negative during the
+	// forecast. If it is false, only the lower bound of the confidence band
+	// does.
+	Likely bool
+}
+
+// A Forecast is the projected balance of an account.
+type Forecast struct {
+	Account dbapi.IBAN
+	// AsOf is the day the forecast starts after, Balance the balance of the
+	// account on that day.
+	AsOf    dbapi.Date
+	Balance dbapi.Money
+	// Points are the projected balances of the following days.
+	Points []ForecastPoint
+	// Recurring are the recurring payments of the account which are part of
+	// the projection.
+	Recurring []RecurringPayment
+	// Overdraft is set if the balance may become negative.
+	Overdraft *OverdraftWarning
+}
+
+// A Forecaster projects the balances of accounts from their transaction
+// history. The projection is the sum of the detected recurring payments at
+// their expected dates (or the first day of the forecast for payments which
+// are due but not booked yet) and the average amount of all other transactions by
+// day of the month. The confidence band widens with the standard deviation of
+// the daily amounts of the other transactions and the tolerance of the
+// recurring payments.
+//
+// The zero value is ready to use. A Forecaster must not be modified while it
+// is used.
+type Forecaster struct {
+	// Days is the number of days to project. It defaults to 30.
+	Days int
+	// History is the number of days before the start of the forecast which are
+	// used for the statistics of the transactions which aren't recurring. It
+	// defaults to 90.
+	History int
+	// Deviations is the width of the confidence band in standard deviations.
+	// It defaults to 1.
+	Deviations float64
+	// Detector detects the recurring payments. If it is nil, a
+	// RecurringDetector with the default settings is used.
+	Detector *RecurringDetector
+}
+
+// Forecast projects the balance of the account for the days after asOf. The
+// balance of the account must be its balance at the end of the day asOf.
+// Transactions of other accounts and transactions booked after asOf are
+// ignored.
+func (f *Forecaster) Forecast(acc dbapi.Account, t dbapi.Transactions, asOf dbapi.Date) *Forecast {
+	days, history, deviations := f.Days, f.History, f.Deviations
+	if days <= 0 {
+		days = 30
+	}
+	if history <= 0 {
+		history = 90
+	}
+	if deviations <= 0 {
+		deviations = 1
+	}
+	detector := f.Detector
+	if detector == nil {
+		detector = new(RecurringDetector)
+	}
+
+	t = t.Filter(func(tx dbapi.Transaction) bool {
+		return tx.OriginIBAN == acc.Iban && !tx.BookingDate.After(asOf)
+	})
+	fc := &Forecast{Account: acc.Iban, AsOf: asOf, Balance: acc.Balance}
+	fc.Recurring = detector.Detect(t, asOf)
+	currency := acc.Balance.Currency()
+
+	// Project the recurring payments onto their expected dates.
+	end := asOf.AddDays(days)
+	recurring := make(map[dbapi.Date]dbapi.Money)
+	tolerance := make(map[dbapi.Date]dbapi.Money)
+	inRecurring := make(map[dbapi.Fingerprint]bool)
+	for _, p := range fc.Recurring {
+		for _, fp := range p.Transactions.Fingerprints() {
+			inRecurring[fp] = true
+		}
+		for n := 1; ; n++ {
+			d := p.Cadence.add(p.First, n)
+			if d.After(end) {
+				break
+			}
+			if d.Before(p.Next) {
+				continue
+			}
+			if !d.After(asOf) {
+				// The payment is due but not booked yet. Since it isn't
+				// reported as missed within the slack of its cadence, it is
+				// still expected, at the earliest on the next day.
+				d = asOf.AddDays(1)
+			}
+			recurring[d] = recurring[d].Add(p.Amount)
+			tolerance[d] = tolerance[d].Add(p.Tolerance)
+		}
+	}
+
+	// Compute the average amount of the other transactions by day of the
+	// month and the standard deviation of their daily sums.
+	start := asOf.AddDays(-history + 1)
+	daily := make(map[dbapi.Date]int64)
+	fps := t.Fingerprints()
+	for i, tx := range t {
+		if inRecurring[fps[i]] || tx.BookingDate.Before(start) {
+			continue
+		}
+		daily[tx.BookingDate] += tx.Amount.Minor()
+	}
+	var sumByDay, countByDay [32]float64
+	var sum, sumSq float64
+	for d := start; !d.After(asOf); d = d.AddDays(1) {
+		v := float64(daily[d])
+		sumByDay[d.Day] += v
+		countByDay[d.Day]++
+		sum += v
+		sumSq += v * v
+	}
+	mean := sum / float64(history)
+	stddev := math.Sqrt(math.Max(sumSq/float64(history)-mean*mean, 0))
+
+	balance := float64(acc.Balance.Minor())
+	var spread float64
+	for i := 1; i <= days; i++ {
+		d := asOf.AddDays(i)
+		if countByDay[d.Day] > 0 {
+			balance += sumByDay[d.Day] / countByDay[d.Day]
+		}
+		balance += float64(recurring[d].Minor())
+		spread += float64(tolerance[d].Minor())
+		band := deviations*stddev*math.Sqrt(float64(i)) + spread
+
+		p := ForecastPoint{
+			Date:    d,
+			Balance: dbapi.NewMoney(int64(math.Round(balance)), currency),
+			Low:     dbapi.NewMoney(int64(math.Round(balance-band)), currency),
+			High:    dbapi.NewMoney(int64(math.Round(balance+band)), currency),
+		}
+		fc.Points = append(fc.Points, p)
+		if fc.Overdraft == nil && p.Low.IsNegative() {
+			fc.Overdraft = &OverdraftWarning{Date: d, Balance: p.Low}
+		}
+		if fc.Overdraft != nil && p.Balance.IsNegative() {
+			fc.Overdraft.Likely = true
+		}
+	}
+	return fc
+}
+
+// ForecastAll fetches the accounts and transactions of the current user and
+// projects the balance of every account for the days after asOf, which should
+// be the current date.
+func (f *Forecaster) ForecastAll(c *dbapi.Client, asOf dbapi.Date) ([]*Forecast, error) {
+	return f.ForecastAllContext(context.Background(), c, asOf)
+}
+
+// ForecastAllContext is like ForecastAll but uses the context ctx for the
+// requests.
+func (f *Forecaster) ForecastAllContext(ctx context.Context, c *dbapi.Client, asOf dbapi.Date) ([]*Forecast, error) {
+	accounts, _, err := c.Accounts.GetAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs, _, err := c.Transactions.GetAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	forecasts := make([]*Forecast, len(*accounts))
+	for i, acc := range *accounts {
+		forecasts[i] = f.Forecast(acc, *txs, asOf)
+	}
+	return forecasts, nil
+}
@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+func TestForecaster_Recurring(t *testing.T) {
+	const iban = "DE10000000000000000454"
+	var txs dbapi.Transactions
+	for _, month := range []string{"07", "08", "09", "10"} {
+		txs = append(txs,
+			dbapi.Transaction{OriginIBAN: iban, Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", BookingDate: mustParseDate("2016-" + month + "-01")},
+			dbapi.Transaction{OriginIBAN: iban, Amount: eur("1000"), CounterPartyName: "ACME GmbH", BookingDate: mustParseDate("2016-" + month + "-15")},
+		)
+	}
+	// Transactions of other accounts are ignored.
+	txs = append(txs, dbapi.Transaction{OriginIBAN: "DE10000000000000000455", Amount: eur("-5000"), BookingDate: mustParseDate("2016-10-20")})
+
+	f := &Forecaster{Days: 20}
+	fc := f.Forecast(dbapi.Account{Iban: iban, Balance: eur("1000")}, txs, mustParseDate("2016-10-27"))
+
+	equals(t, dbapi.IBAN(iban), fc.Account)
+	equals(t, 2, len(fc.Recurring))
+	equals(t, 20, len(fc.Points))
+	equals(t, ForecastPoint{Date: mustParseDate("2016-10-28"), Balance: eur("1000"), Low: eur("1000"), High: eur("1000")}, fc.Points[0])
+	equals(t, mustParseDate("2016-11-01"), fc.Points[4].Date)
+	equals(t, eur("-500"), fc.Points[4].Balance)
+	equals(t, eur("-500"), fc.Points[17].Balance)
+	equals(t, mustParseDate("2016-11-15"), fc.Points[18].Date)
+	equals(t, eur("500"), fc.Points[18].Balance)
+	equals(t, &OverdraftWarning{Date: mustParseDate("2016-11-01"), Balance: eur("-500"), Likely: true}, fc.Overdraft)
+}
+
+func TestForecaster_Outstanding(t *testing.T) {
+	const iban = "DE10000000000000000454"
+	var txs dbapi.Transactions
+	for _, month := range []string{"07", "08", "09", "10"} {
+		txs = append(txs, dbapi.Transaction{OriginIBAN: iban, Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", BookingDate: mustParseDate("2016-" + month + "-01")})
+	}
+
+	// The rent of November is due since November 1 but not booked yet.
+	f := &Forecaster{Days: 20}
+	fc := f.Forecast(dbapi.Account{Iban: iban, Balance: eur("1000")}, txs, mustParseDate("2016-11-03"))
+
+	equals(t, 1, len(fc.Recurring))
+	equals(t, 0, len(fc.Recurring[0].Events))
+	equals(t, mustParseDate("2016-11-01"), fc.Recurring[0].Next)
+	equals(t, ForecastPoint{Date: mustParseDate("2016-11-04"), Balance: eur("-500"), Low: eur("-500"), High: eur("-500")}, fc.Points[0])
+	equals(t, eur("-500"), fc.Points[19].Balance)
+	equals(t, &OverdraftWarning{Date: mustParseDate("2016-11-04"), Balance: eur("-500"), Likely: true}, fc.Overdraft)
+}
+
+func TestForecaster_Statistics(t *testing.T) {
+	const iban = "DE10000000000000000454"
+	asOf := mustParseDate("2016-10-27")
+	var txs dbapi.Transactions
+	for d := asOf.AddDays(-89); !d.After(asOf); d = d.AddDays(1) {
+		amount := eur("-10")
+		if d.Day%2 == 0 {
+			amount = eur("-20")
+		}
+		txs = append(txs, dbapi.Transaction{OriginIBAN: iban, Amount: amount, CounterPartyName: "Bäckerei", BookingDate: d})
+	}
+
+	fc := new(Forecaster).Forecast(dbapi.Account{Iban: iban, Balance: eur("100")}, txs, asOf)
+
+	equals(t, 30, len(fc.Points))
+	equals(t, 0, len(fc.Recurring))
+	// October 28 is an even day.
+	equals(t, eur("80"), fc.Points[0].Balance)
+	equals(t, eur("70"), fc.Points[1].Balance)
+	assert(t, fc.Points[0].Low.Cmp(fc.Points[0].Balance) < 0, "expected a confidence band")
+	assert(t, fc.Points[0].High.Cmp(fc.Points[0].Balance) > 0, "expected a confidence band")
+	bandWidth := func(p ForecastPoint) int64 { return p.High.Sub(p.Low).Minor() }
+	assert(t, bandWidth(fc.Points[29]) > bandWidth(fc.Points[0]), "expected the confidence band to widen")
+
+	assert(t, fc.Overdraft != nil, "expected an overdraft warning")
+	assert(t, fc.Overdraft.Likely, "expected a likely overdraft")
+	assert(t, fc.Overdraft.Balance.IsNegative(), "expected a negative balance")
+	// The expected balance becomes negative on November 4, the lower bound
+	// earlier.
+	assert(t, fc.Overdraft.Date.Before(mustParseDate("2016-11-04")), "unexpected overdraft date %s", fc.Overdraft.Date)
+}
+
+func TestForecaster_NoOverdraft(t *testing.T) {
+	fc := new(Forecaster).Forecast(dbapi.Account{Iban: "DE10000000000000000454", Balance: eur("100")}, nil, mustParseDate("2016-10-27"))
+	equals(t, 30, len(fc.Points))
+	equals(t, eur("100"), fc.Points[29].Balance)
+	assert(t, fc.Overdraft == nil, "expected no overdraft warning")
+}
+
+func TestForecaster_ForecastAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/cashAccounts", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"iban":"DE10000000000000000454","balance":250},{"iban":"DE10000000000000000455","balance":100}]`)
+	})
+	testMux.HandleFunc("/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"originIBAN":"DE10000000000000000455","amount":50,"counterPartyName":"Claudia Klar","usage":"Sparen Samuel","bookingDate":"2016-10-01"},{"originIBAN":"DE10000000000000000455","amount":50,"counterPartyName":"Claudia Klar","usage":"Sparen Samuel","bookingDate":"2016-09-01"},{"originIBAN":"DE10000000000000000455","amount":50,"counterPartyName":"Claudia Klar","usage":"Sparen Samuel","bookingDate":"2016-08-01"}]`)
+	})
+
+	act, err := new(Forecaster).ForecastAll(testClient, mustParseDate("2016-10-27"))
+	ok(t, err)
+	equals(t, 2, len(act))
+	equals(t, dbapi.IBAN("DE10000000000000000454"), act[0].Account)
+	equals(t, eur("250"), act[0].Points[29].Balance)
+	equals(t, dbapi.IBAN("DE10000000000000000455"), act[1].Account)
+	equals(t, 1, len(act[1].Recurring))
+	equals(t, eur("150"), act[1].Points[29].Balance)
+}
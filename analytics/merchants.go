@@ -0,0 +1,345 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+// ErrInvalidMerchant is raised when a merchant or an alias is invalid (e.g.
+// without a name).
+var ErrInvalidMerchant = errors.New("Invalid merchant")
+
+// storeNumber matches the store numbers card terminals append to the names of
+// merchants, like "1234" or "#1234".
+var storeNumber = regexp.MustCompile(`^#?\d{1,6}$`)
+
+// storeLabels are the labels which precede store numbers, like "Filiale" in
+// "REWE Filiale 1234".
+var storeLabels = map[string]bool{
+	"fil": true, "filiale": true, "nr": true, "markt-nr": true,
+}
+
+// cities are the cities which card terminals append to the names of
+// merchants, lowercase and split into words.
+var cities = [][]string{
+	{"frankfurt", "am", "main"}, {"frankfurt/main"}, {"frankfurt/m"}, {"frankfurt"},
+	{"berlin"}, {"hamburg"}, {"münchen"}, {"muenchen"}, {"köln"}, {"koeln"},
+	{"stuttgart"}, {"düsseldorf"}, {"duesseldorf"}, {"dortmund"}, {"essen"},
+	{"leipzig"}, {"bremen"}, {"dresden"}, {"hannover"}, {"nürnberg"},
+	{"nuernberg"}, {"duisburg"}, {"bochum"}, {"wuppertal"}, {"bielefeld"},
+	{"bonn"}, {"münster"}, {"muenster"}, {"mannheim"}, {"karlsruhe"},
+	{"augsburg"}, {"wiesbaden"}, {"mainz"}, {"offenbach"}, {"darmstadt"},
+	{"heidelberg"}, {"freiburg"}, {"kiel"}, {"potsdam"},
+}
+
+// CleanCounterPartyName cleans up the name of a counterparty as it appears in
+// transactions: whitespace is normalized, store numbers and city suffixes are
+// stripped and names written in uppercase are converted to title case, except
+// for words of up to three letters which are likely abbreviations:
+//
+//	"NETTO MARKEN-DISCOUNT 1234" -> "Netto Marken-Discount"
+//	"Alnatura Frankfurt"         -> "Alnatura"
+//	"JET TANKSTELLE #42"         -> "JET Tankstelle"
+//
+// At least one word of the name is always kept.
+func CleanCounterPartyName(s string) string {
+	words := strings.Fields(s)
+	for len(words) > 1 {
+		last := strings.ToLower(strings.TrimRight(words[len(words)-1], ".,"))
+		if storeNumber.MatchString(last) || storeLabels[last] {
+			words = words[:len(words)-1]
+			continue
+		}
+		if n := citySuffix(words); n > 0 && n < len(words) {
+			words = words[:len(words)-n]
+			continue
+		}
+		break
+	}
+
+	name := strings.Join(words, " ")
+	if strings.IndexFunc(name, unicode.IsLower) >= 0 {
+		return name
+	}
+	for i, w := range words {
+		parts := strings.Split(w, "-")
+		for j, p := range parts {
+			if len([]rune(p)) > 3 {
+				r := []rune(strings.ToLower(p))
+				r[0] = unicode.ToUpper(r[0])
+				parts[j] = string(r)
+			}
+		}
+		words[i] = strings.Join(parts, "-")
+	}
+	return strings.Join(words, " ")
+}
+
+// citySuffix returns the number of words of the city the words end with or
+// zero if they don't end with a city.
+func citySuffix(words []string) int {
+	for _, city := range cities {
+		if len(city) > len(words) {
+			continue
+		}
+		suffix := words[len(words)-len(city):]
+		match := true
+		for i, w := range city {
+			if strings.ToLower(suffix[i]) != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return len(city)
+		}
+	}
+	return 0
+}
+
+// merchantKey returns the key a name is looked up by in a merchant directory.
+func merchantKey(name string) string {
+	return strings.ToLower(CleanCounterPartyName(name))
+}
+
+// A Merchant is the canonical form of a counterparty which appears under
+// different names in transactions.
+type Merchant struct {
+	// Name is the canonical name of the merchant.
+	Name     string   `json:"name"`
+	Category Category `json:"category,omitempty"`
+	Website  string   `json:"website,omitempty"`
+	// Aliases are the names under which the merchant appears in transactions,
+	// besides its name.
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// A MerchantDirectory maps the names of counterparties to merchants. Names are
+// cleaned with CleanCounterPartyName before they are looked up or learned.
+type MerchantDirectory interface {
+	// Lookup returns the merchant the name belongs to. The second return value
+	// reports whether the merchant is known.
+	Lookup(name string) (Merchant, bool)
+	// Learn records that the name is an alias of the merchant, for example
+	// after the user corrected the merchant of a transaction. Unknown merchants
+	// are added to the directory, the category and website of known merchants
+	// are updated if they are set.
+	Learn(name string, m Merchant) error
+}
+
+// MemoryMerchantDirectory is a MerchantDirectory which keeps the merchants in
+// memory. A name belongs to a merchant if it is the name or an alias of the
+// merchant or starts with one of them, followed by further words. The longest
+// match wins, so "NETTO MARKEN-DISCOUNT" belongs to a merchant with the alias
+// "Netto". Use Merchants to persist the merchants including the learned
+// aliases.
+type MemoryMerchantDirectory struct {
+	mu        sync.RWMutex
+	merchants map[string]*Merchant
+	aliases   map[string]string
+}
+
+// NewMemoryMerchantDirectory creates and returns a new MemoryMerchantDirectory
+// with the merchants, which may be empty. An error wrapping ErrInvalidMerchant
+// is returned if a merchant has no name.
+//
+// Use DefaultMerchants to start with a set of common German merchants:
+//
+//	dir, err := analytics.NewMemoryMerchantDirectory(analytics.DefaultMerchants()...)
+func NewMemoryMerchantDirectory(merchants ...Merchant) (*MemoryMerchantDirectory, error) {
+	d := &MemoryMerchantDirectory{
+		merchants: make(map[string]*Merchant),
+		aliases:   make(map[string]string),
+	}
+	for _, m := range merchants {
+		if err := d.Learn(m.Name, m); err != nil {
+			return nil, err
+		}
+		for _, alias := range m.Aliases {
+			if err := d.Learn(alias, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d, nil
+}
+
+// Lookup implements the MerchantDirectory interface.
+func (d *MemoryMerchantDirectory) Lookup(name string) (Merchant, bool) {
+	key := merchantKey(name)
+	if key == "" {
+		return Merchant{}, false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if m, ok := d.aliases[key]; ok {
+		return d.merchants[m].copy(), true
+	}
+	var best string
+	for alias := range d.aliases {
+		if len(alias) > len(best) && hasWordPrefix(key, alias) {
+			best = alias
+		}
+	}
+	if best == "" {
+		return Merchant{}, false
+	}
+	return d.merchants[d.aliases[best]].copy(), true
+}
+
+// Learn implements the MerchantDirectory interface. An error wrapping
+// ErrInvalidMerchant is returned if the name or the name of the merchant is
+// empty.
+func (d *MemoryMerchantDirectory) Learn(name string, m Merchant) error {
+	key, alias := merchantKey(m.Name), CleanCounterPartyName(name)
+	if key == "" {
+		return fmt.Errorf("%w: no name", ErrInvalidMerchant)
+	}
+	if alias == "" {
+		return fmt.Errorf("%w %q: empty alias", ErrInvalidMerchant, m.Name)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	known, ok := d.merchants[key]
+	if !ok {
+		known = &Merchant{Name: dbapi.NormalizeSpace(m.Name)}
+		d.merchants[key] = known
+		d.aliases[key] = key
+	}
+	if m.Category != Uncategorized {
+		known.Category = m.Category
+	}
+	if m.Website != "" {
+		known.Website = m.Website
+	}
+	aliasKey := strings.ToLower(alias)
+	if prev, ok := d.aliases[aliasKey]; ok && prev != key {
+		// The alias belonged to another merchant, which the user corrected.
+		d.merchants[prev].removeAlias(aliasKey)
+	}
+	d.aliases[aliasKey] = key
+	if aliasKey != key && !known.hasAlias(aliasKey) {
+		known.Aliases = append(known.Aliases, alias)
+	}
+	return nil
+}
+
+// Merchants returns all merchants ordered by name.
+func (d *MemoryMerchantDirectory) Merchants() []Merchant {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	merchants := make([]Merchant, 0, len(d.merchants))
+	for _, m := range d.merchants {
+		merchants = append(merchants, m.copy())
+	}
+	sort.Slice(merchants, func(i, j int) bool {
+		return strings.ToLower(merchants[i].Name) < strings.ToLower(merchants[j].Name)
+	})
+	return merchants
+}
+
+// copy returns a copy of the merchant which doesn't share its aliases.
+func (m *Merchant) copy() Merchant {
+	c := *m
+	c.Aliases = append([]string(nil), m.Aliases...)
+	return c
+}
+
+// hasAlias reports whether the merchant has the lowercase alias.
+func (m *Merchant) hasAlias(alias string) bool {
+	for _, a := range m.Aliases {
+		if strings.ToLower(a) == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// removeAlias removes the lowercase alias from the merchant.
+func (m *Merchant) removeAlias(alias string) {
+	aliases := m.Aliases[:0]
+	for _, a := range m.Aliases {
+		if strings.ToLower(a) != alias {
+			aliases = append(aliases, a)
+		}
+	}
+	m.Aliases = aliases
+}
+
+// hasWordPrefix reports whether s starts with the words of prefix.
+func hasWordPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	rest := s[len(prefix):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '-'
+}
+
+// DefaultMerchants returns a set of common German merchants.
+func DefaultMerchants() []Merchant {
+	return []Merchant{
+		{Name: "Netto", Category: Groceries, Website: "https://www.netto-online.de", Aliases: []string{"Netto Marken-Discount"}},
+		{Name: "Lidl", Category: Groceries, Website: "https://www.lidl.de"},
+		{Name: "Aldi", Category: Groceries, Website: "https://www.aldi.de", Aliases: []string{"Aldi Nord", "Aldi Süd", "Aldi GmbH"}},
+		{Name: "Rewe", Category: Groceries, Website: "https://www.rewe.de", Aliases: []string{"Rewe Markt"}},
+		{Name: "Edeka", Category: Groceries, Website: "https://www.edeka.de"},
+		{Name: "Alnatura", Category: Groceries, Website: "https://www.alnatura.de"},
+		{Name: "JET", Category: Fuel, Website: "https://www.jet.de", Aliases: []string{"JET Tankstelle"}},
+		{Name: "Aral", Category: Fuel, Website: "https://www.aral.de"},
+		{Name: "Shell", Category: Fuel, Website: "https://www.shell.de"},
+		{Name: "Schwäbisch Hall", Category: Housing, Website: "https://www.schwaebisch-hall.de", Aliases: []string{"Bausparkasse Schwäbisch Hall"}},
+		{Name: "Toys R Us", Category: Shopping, Website: "https://www.toysrus.de", Aliases: []string{"Toys\"R\"Us"}},
+		{Name: "Amazon", Category: Shopping, Website: "https://www.amazon.de", Aliases: []string{"Amazon.de", "AMZN Mktp DE", "Amazon EU S.a.r.l."}},
+	}
+}
+
+// A CounterPartyNormalizer resolves the names of counterparties to the names
+// of merchants. Names without a merchant are cleaned with
+// CleanCounterPartyName. The zero value is ready to use and only cleans names.
+// A CounterPartyNormalizer must not be modified while it is used.
+//
+// Its CounterParty method can be used to group transactions by merchant:
+//
+//	n := &analytics.CounterPartyNormalizer{Directory: dir}
+//	a := &analytics.Analyzer{CounterParty: n.CounterParty}
+type CounterPartyNormalizer struct {
+	// Directory maps names to merchants. If it is nil, names are only cleaned.
+	Directory MerchantDirectory
+}
+
+// Normalize returns the name of the merchant the name belongs to or the
+// cleaned name if the merchant is unknown.
+func (n *CounterPartyNormalizer) Normalize(name string) string {
+	if n.Directory != nil {
+		if m, ok := n.Directory.Lookup(name); ok {
+			return m.Name
+		}
+	}
+	return CleanCounterPartyName(name)
+}
+
+// Merchant returns the merchant of the transaction. The second return value
+// reports whether the merchant is known.
+func (n *CounterPartyNormalizer) Merchant(t dbapi.Transaction) (Merchant, bool) {
+	if n.Directory == nil {
+		return Merchant{}, false
+	}
+	return n.Directory.Lookup(t.CounterPartyName)
+}
+
+// CounterParty returns the normalized counterparty name of the transaction or
+// its counterparty IBAN if it has no name.
+func (n *CounterPartyNormalizer) CounterParty(t dbapi.Transaction) string {
+	if name := n.Normalize(t.CounterPartyName); name != "" {
+		return name
+	}
+	return string(t.CounterPartyIBAN)
+}
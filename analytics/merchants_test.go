@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+func TestCleanCounterPartyName(t *testing.T) {
+	mockData := []struct {
+		input string
+		exp   string
+	}{
+		{"Netto", "Netto"},
+		{"NETTO MARKEN-DISCOUNT 1234", "Netto Marken-Discount"},
+		{"Alnatura Frankfurt", "Alnatura"},
+		{"ALNATURA  FRANKFURT AM MAIN", "Alnatura"},
+		{"JET TANKSTELLE #42", "JET Tankstelle"},
+		{"REWE Filiale 1234 Berlin", "Rewe"},
+		{"  Schwäbisch   Hall ", "Schwäbisch Hall"},
+		{"SCHWÄBISCH HALL", "Schwäbisch Hall"},
+		// At least one word is kept.
+		{"Frankfurt", "Frankfurt"},
+		{"1234", "1234"},
+		{"", ""},
+	}
+
+	for _, tt := range mockData {
+		equals(t, tt.exp, CleanCounterPartyName(tt.input))
+	}
+}
+
+func TestMemoryMerchantDirectory_Lookup(t *testing.T) {
+	dir, err := NewMemoryMerchantDirectory(DefaultMerchants()...)
+	ok(t, err)
+
+	mockData := []struct {
+		input string
+		exp   string
+	}{
+		{"Netto", "Netto"},
+		{"NETTO MARKEN-DISCOUNT 1234", "Netto"},
+		{"Alnatura Frankfurt", "Alnatura"},
+		{"ALDI SUED", "Aldi"},
+		{"AMZN Mktp DE", "Amazon"},
+		{"JET TANKSTELLE #42", "JET"},
+	}
+
+	for _, tt := range mockData {
+		m, found := dir.Lookup(tt.input)
+		assert(t, found, "expected a merchant for %q", tt.input)
+		equals(t, tt.exp, m.Name)
+	}
+
+	m, _ := dir.Lookup("Netto 1234")
+	equals(t, Groceries, m.Category)
+	equals(t, "https://www.netto-online.de", m.Website)
+
+	// Names are matched word by word.
+	_, found := dir.Lookup("Shellfish Bar")
+	assert(t, !found, "expected no merchant")
+	_, found = dir.Lookup("")
+	assert(t, !found, "expected no merchant")
+}
+
+func TestMemoryMerchantDirectory_Learn(t *testing.T) {
+	dir, err := NewMemoryMerchantDirectory(Merchant{Name: "Alnatura", Category: Groceries, Website: "https://www.alnatura.de"})
+	ok(t, err)
+
+	_, found := dir.Lookup("ALNATURA PRODUKTIONS 12")
+	assert(t, found, "expected a merchant")
+	_, found = dir.Lookup("Bio Markt Sonnenschein")
+	assert(t, !found, "expected no merchant")
+
+	// Learning an alias keeps the metadata of a known merchant.
+	ok(t, dir.Learn("Bio Markt Sonnenschein 7", Merchant{Name: "alnatura"}))
+	m, found := dir.Lookup("Bio Markt Sonnenschein Köln")
+	assert(t, found, "expected a merchant")
+	equals(t, Merchant{Name: "Alnatura", Category: Groceries, Website: "https://www.alnatura.de", Aliases: []string{"Bio Markt Sonnenschein"}}, m)
+
+	// Learning an alias of another merchant moves it.
+	ok(t, dir.Learn("Bio Markt Sonnenschein", Merchant{Name: "Sonnenschein", Category: Shopping}))
+	m, _ = dir.Lookup("Bio Markt Sonnenschein")
+	equals(t, "Sonnenschein", m.Name)
+	equals(t, []Merchant{
+		{Name: "Alnatura", Category: Groceries, Website: "https://www.alnatura.de"},
+		{Name: "Sonnenschein", Category: Shopping, Aliases: []string{"Bio Markt Sonnenschein"}},
+	}, dir.Merchants())
+
+	err = dir.Learn("Foo", Merchant{})
+	assert(t, errors.Is(err, ErrInvalidMerchant), "expected ErrInvalidMerchant, got %v", err)
+	err = dir.Learn(" ", Merchant{Name: "Foo"})
+	assert(t, errors.Is(err, ErrInvalidMerchant), "expected ErrInvalidMerchant, got %v", err)
+}
+
+func TestCounterPartyNormalizer(t *testing.T) {
+	dir, err := NewMemoryMerchantDirectory(DefaultMerchants()...)
+	ok(t, err)
+	n := &CounterPartyNormalizer{Directory: dir}
+
+	equals(t, "Netto", n.Normalize("NETTO MARKEN-DISCOUNT 1234"))
+	equals(t, "Bäckerei Müller", n.Normalize("BÄCKEREI MÜLLER 3 Frankfurt"))
+	equals(t, "DE10000000000000000455", n.CounterParty(dbapi.Transaction{CounterPartyIBAN: "DE10000000000000000455"}))
+
+	m, found := n.Merchant(dbapi.Transaction{CounterPartyName: "Alnatura Frankfurt"})
+	assert(t, found, "expected a merchant")
+	equals(t, "Alnatura", m.Name)
+
+	// Without a directory, names are only cleaned.
+	n = new(CounterPartyNormalizer)
+	equals(t, "Netto Marken-Discount", n.Normalize("NETTO MARKEN-DISCOUNT 1234"))
+	_, found = n.Merchant(dbapi.Transaction{CounterPartyName: "Netto"})
+	assert(t, !found, "expected no merchant")
+
+	// Transactions are grouped by merchant.
+	txs := dbapi.Transactions{
+		{Amount: eur("-10"), CounterPartyName: "Netto", BookingDate: mustParseDate("2016-10-01")},
+		{Amount: eur("-20"), CounterPartyName: "NETTO MARKEN-DISCOUNT 1234", BookingDate: mustParseDate("2016-10-02")},
+	}
+	a := &Analyzer{CounterParty: (&CounterPartyNormalizer{Directory: dir}).CounterParty}
+	r := a.Analyze(txs)
+	equals(t, 1, len(r.CounterParties))
+	equals(t, "Netto", r.CounterParties[0].CounterParty)
+	equals(t, eur("30"), r.CounterParties[0].Spending)
+}
@@ -0,0 +1,286 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+// Cadence is the interval at which a recurring payment is due.
+type Cadence string
+
+// Detected cadences.
+const (
+	Weekly    Cadence = "weekly"
+	Monthly   Cadence = "monthly"
+	Quarterly Cadence = "quarterly"
+	Yearly    Cadence = "yearly"
+)
+
+func (c Cadence) String() string {
+	return string(c)
+}
+
+// cadences lists the cadences with the range of intervals in days by which they
+// are detected and the number of days a payment may deviate from its schedule
+// (e.g. because it was booked on the next business day).
+var cadences = []struct {
+	cadence  Cadence
+	min, max int
+	slack    int
+}{
+	{Weekly, 5, 9, 1},
+	{Monthly, 25, 36, 4},
+	{Quarterly, 80, 100, 7},
+	{Yearly, 350, 380, 10},
+}
+
+// add returns the date n intervals of the cadence after d. Months are added
+// with dbapi.Date.AddMonths, so a payment due on the 31st is expected on the
+// last day of shorter months.
+func (c Cadence) add(d dbapi.Date, n int) dbapi.Date {
+	switch c {
+	case Weekly:
+		return d.AddDays(7 * n)
+	case Quarterly:
+		return d.AddMonths(3 * n)
+	case Yearly:
+		return d.AddMonths(12 * n)
+	}
+	return d.AddMonths(n)
+}
+
+// volatileAmount is the relative change of the amount between two payments
+// above which the amount is considered volatile.
+const volatileAmount = 0.25
+
+// RecurringEventType is the type of a RecurringEvent.
+type RecurringEventType string
+
+// Available types of recurring events.
+const (
+	// PaymentMissed means a payment wasn't booked when it was expected.
+	PaymentMissed RecurringEventType = "missed"
+	// AmountChanged means the amount of a payment differs from the amount of
+	// the previous payment by more than the tolerance.
+	AmountChanged RecurringEventType = "amount_changed"
+)
+
+// A RecurringEvent is a notable deviation of a recurring payment from its
+// schedule or amount.
+type RecurringEvent struct {
+	Type RecurringEventType
+	// Date is the expected date of a missed payment or the booking date of a
+	// payment with a changed amount.
+	Date dbapi.Date
+	// Expected is the amount which was expected, Actual the amount which was
+	// booked. Actual is zero for missed payments.
+	Expected dbapi.Money
+	Actual   dbapi.Money
+}
+
+// A RecurringPayment is a series of payments to or from the same counterparty
+// at a regular cadence, like a rent, a loan installment, a subscription or a
+// standing order.
+type RecurringPayment struct {
+	CounterParty     string
+	CounterPartyIBAN dbapi.IBAN
+	// Account is the IBAN of the account of the user.
+	Account dbapi.IBAN
+	Cadence Cadence
+	// Amount is the typical amount, which is the median of the amounts since
+	// the last change of the amount. Tolerance is the largest deviation from
+	// it during that time.
+	Amount    dbapi.Money
+	Tolerance dbapi.Money
+	// First and Last are the booking dates of the first and last payment.
+	First, Last dbapi.Date
+	// Next is the date the next payment is expected at.
+	Next dbapi.Date
+	// Transactions are the payments in chronological order.
+	Transactions dbapi.Transactions
+	// Events are the missed payments and amount changes in chronological
+	// order.
+	Events []RecurringEvent
+}
+
+// A RecurringDetector detects recurring payments in transactions. The zero
+// value is ready to use. A RecurringDetector must not be modified while it is
+// used.
+type RecurringDetector struct {
+	// MinOccurrences is the number of payments which are required to detect a
+	// recurring payment. It defaults to 3.
+	MinOccurrences int
+	// AmountTolerance is the relative deviation of an amount from the previous
+	// one which isn't considered a change (e.g. 0.01 for 1 %). It defaults to
+	// 0.01.
+	AmountTolerance float64
+	// CounterParty, if set, returns the name transactions are grouped by. By
+	// default, the counterparty name is used, ignoring case and whitespace, or
+	// the counterparty IBAN if there is no name. Use
+	// CounterPartyNormalizer.CounterParty to group by merchant.
+	CounterParty func(dbapi.Transaction) string
+}
+
+// DetectRecurring detects recurring payments with the default settings of a
+// RecurringDetector.
+func DetectRecurring(t dbapi.Transactions, asOf dbapi.Date) []RecurringPayment {
+	return new(RecurringDetector).Detect(t, asOf)
+}
+
+// Detect groups the transactions by account, counterparty and direction and
+// returns the groups whose payments are booked at a regular cadence and whose
+// amount is mostly stable. Payments which were expected before the date asOf
+// but not booked are reported as missed, so asOf should be the date the
+// transactions were fetched at. If asOf is the zero date, only gaps between
+// payments are reported as missed.
+//
+// The recurring payments are ordered by counterparty and account.
+func (d *RecurringDetector) Detect(t dbapi.Transactions, asOf dbapi.Date) []RecurringPayment {
+	type key struct {
+		account      dbapi.IBAN
+		counterParty string
+		sign         int
+	}
+	groups := make(map[key]dbapi.Transactions)
+	var keys []key
+	for _, tx := range t {
+		if tx.Amount.IsZero() {
+			continue
+		}
+		k := key{tx.OriginIBAN, d.counterParty(tx), tx.Amount.Sign()}
+		if groups[k] == nil {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], tx)
+	}
+
+	var r []RecurringPayment
+	for _, k := range keys {
+		if p, ok := d.detect(groups[k], asOf); ok {
+			r = append(r, p)
+		}
+	}
+	sort.SliceStable(r, func(i, j int) bool {
+		if a, b := strings.ToLower(r[i].CounterParty), strings.ToLower(r[j].CounterParty); a != b {
+			return a < b
+		}
+		return r[i].Account < r[j].Account
+	})
+	return r
+}
+
+// counterParty returns the key transactions are grouped by.
+func (d *RecurringDetector) counterParty(t dbapi.Transaction) string {
+	switch {
+	case d.CounterParty != nil:
+		return d.CounterParty(t)
+	case strings.TrimSpace(t.CounterPartyName) != "":
+		return strings.ToLower(dbapi.NormalizeSpace(t.CounterPartyName))
+	}
+	return string(t.CounterPartyIBAN)
+}
+
+// detect checks whether the transactions of a group are a recurring payment.
+func (d *RecurringDetector) detect(t dbapi.Transactions, asOf dbapi.Date) (RecurringPayment, bool) {
+	minOccurrences, tolerance := d.MinOccurrences, d.AmountTolerance
+	if minOccurrences < 2 {
+		minOccurrences = 3
+	}
+	if tolerance <= 0 {
+		tolerance = 0.01
+	}
+	if len(t) < minOccurrences {
+		return RecurringPayment{}, false
+	}
+	t = append(dbapi.Transactions(nil), t...)
+	t.SortByDate()
+
+	// Detect the cadence by the median interval between the payments.
+	intervals := make([]int, len(t)-1)
+	for i := 1; i < len(t); i++ {
+		intervals[i-1] = t[i].BookingDate.DaysSince(t[i-1].BookingDate)
+	}
+	sort.Ints(intervals)
+	median := intervals[len(intervals)/2]
+	ci := -1
+	for i, c := range cadences {
+		if median >= c.min && median <= c.max {
+			ci = i
+		}
+	}
+	if ci < 0 {
+		return RecurringPayment{}, false
+	}
+	cadence, slack := cadences[ci].cadence, cadences[ci].slack
+
+	last := t[len(t)-1]
+	p := RecurringPayment{
+		CounterParty:     dbapi.NormalizeSpace(last.CounterPartyName),
+		CounterPartyIBAN: last.CounterPartyIBAN,
+		Account:          last.OriginIBAN,
+		Cadence:          cadence,
+		First:            t[0].BookingDate,
+		Last:             last.BookingDate,
+		Transactions:     t,
+	}
+
+	// Follow the schedule, which is anchored at the first payment. Every
+	// payment must be booked close to a scheduled date, scheduled dates
+	// without payment are missed.
+	n, volatile, regime := 1, 0, 0
+	for i := 1; i < len(t); i++ {
+		date, prev := t[i].BookingDate, t[i-1].Amount
+		for ; cadence.add(p.First, n).AddDays(slack).Before(date); n++ {
+			p.Events = append(p.Events, RecurringEvent{Type: PaymentMissed, Date: cadence.add(p.First, n), Expected: prev})
+		}
+		if abs(date.DaysSince(cadence.add(p.First, n))) > slack {
+			return RecurringPayment{}, false
+		}
+		n++
+
+		diff := t[i].Amount.Sub(prev).Abs().Float64() / prev.Abs().Float64()
+		if diff > tolerance {
+			p.Events = append(p.Events, RecurringEvent{Type: AmountChanged, Date: date, Expected: prev, Actual: t[i].Amount})
+			regime = i
+		}
+		if diff > volatileAmount {
+			volatile++
+		}
+	}
+	// Payments whose amount varies widely most of the time aren't recurring,
+	// like weekly grocery shopping at the same store.
+	if volatile > (len(t)-1)/2 {
+		return RecurringPayment{}, false
+	}
+
+	p.Next = cadence.add(p.First, n)
+	for !asOf.IsZero() && p.Next.AddDays(slack).Before(asOf) {
+		p.Events = append(p.Events, RecurringEvent{Type: PaymentMissed, Date: p.Next, Expected: last.Amount})
+		n++
+		p.Next = cadence.add(p.First, n)
+	}
+
+	amounts := make([]dbapi.Money, 0, len(t)-regime)
+	for _, tx := range t[regime:] {
+		amounts = append(amounts, tx.Amount)
+	}
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i].Cmp(amounts[j]) < 0 })
+	p.Amount = amounts[len(amounts)/2]
+	p.Tolerance = dbapi.NewMoney(0, p.Amount.Currency())
+	for _, a := range amounts {
+		if dev := a.Sub(p.Amount).Abs(); dev.Cmp(p.Tolerance) > 0 {
+			p.Tolerance = dev
+		}
+	}
+	return p, true
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+func TestDetectRecurring(t *testing.T) {
+	savings := func(date string) dbapi.Transaction {
+		return dbapi.Transaction{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: mustParseDate(date)}
+	}
+	rent := func(date, amount string) dbapi.Transaction {
+		return dbapi.Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur(amount), CounterPartyName: "Schwäbisch Hall", CounterPartyIBAN: "DE89370400440532013000", BookingDate: mustParseDate(date)}
+	}
+	netto := func(date, amount string) dbapi.Transaction {
+		return dbapi.Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur(amount), CounterPartyName: "Netto", BookingDate: mustParseDate(date)}
+	}
+
+	txs := dbapi.Transactions{
+		savings("2016-10-01"), savings("2016-09-01"), savings("2016-08-01"), savings("2016-07-01"),
+		// Booked on the next business day and the amount changed once.
+		rent("2016-10-04", "-1550"), rent("2016-09-01", "-1500"), rent("2016-08-01", "-1500.2"), rent("2016-07-01", "-1500"),
+		// Irregular dates and amounts.
+		netto("2016-10-27", "-35.56"), netto("2016-10-12", "-12.3"), netto("2016-09-29", "-80.1"), netto("2016-09-02", "-8"),
+	}
+
+	act := DetectRecurring(txs, mustParseDate("2016-10-20"))
+	equals(t, 2, len(act))
+
+	s := act[0]
+	equals(t, "Claudia Klar", s.CounterParty)
+	equals(t, dbapi.IBAN("DE10000000000000000455"), s.Account)
+	equals(t, Monthly, s.Cadence)
+	equals(t, eur("50"), s.Amount)
+	equals(t, eur("0"), s.Tolerance)
+	equals(t, mustParseDate("2016-07-01"), s.First)
+	equals(t, mustParseDate("2016-10-01"), s.Last)
+	equals(t, mustParseDate("2016-11-01"), s.Next)
+	equals(t, 4, len(s.Transactions))
+	equals(t, 0, len(s.Events))
+
+	r := act[1]
+	equals(t, "Schwäbisch Hall", r.CounterParty)
+	equals(t, dbapi.IBAN("DE89370400440532013000"), r.CounterPartyIBAN)
+	equals(t, Monthly, r.Cadence)
+	equals(t, eur("-1550"), r.Amount)
+	equals(t, []RecurringEvent{{Type: AmountChanged, Date: mustParseDate("2016-10-04"), Expected: eur("-1500"), Actual: eur("-1550")}}, r.Events)
+}
+
+func TestDetectRecurring_Missed(t *testing.T) {
+	sub := func(date string) dbapi.Transaction {
+		return dbapi.Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-9.99"), CounterPartyName: "Streaming GmbH", BookingDate: mustParseDate(date)}
+	}
+	txs := dbapi.Transactions{sub("2016-01-31"), sub("2016-02-29"), sub("2016-04-30"), sub("2016-05-31")}
+
+	act := DetectRecurring(txs, mustParseDate("2016-08-10"))
+	equals(t, 1, len(act))
+	p := act[0]
+	equals(t, Monthly, p.Cadence)
+	equals(t, []RecurringEvent{
+		{Type: PaymentMissed, Date: mustParseDate("2016-03-31"), Expected: eur("-9.99")},
+		{Type: PaymentMissed, Date: mustParseDate("2016-06-30"), Expected: eur("-9.99")},
+		{Type: PaymentMissed, Date: mustParseDate("2016-07-31"), Expected: eur("-9.99")},
+	}, p.Events)
+	equals(t, mustParseDate("2016-08-31"), p.Next)
+
+	// Without a reference date, only gaps between payments are missed.
+	p = DetectRecurring(txs, dbapi.Date{})[0]
+	equals(t, 1, len(p.Events))
+	equals(t, mustParseDate("2016-06-30"), p.Next)
+}
+
+func TestRecurringDetector_Cadences(t *testing.T) {
+	series := func(start string, cadence Cadence, n int) dbapi.Transactions {
+		var txs dbapi.Transactions
+		for i := 0; i < n; i++ {
+			txs = append(txs, dbapi.Transaction{Amount: eur("-10"), CounterPartyName: "Verein", BookingDate: cadence.add(mustParseDate(start), i)})
+		}
+		return txs
+	}
+
+	d := &RecurringDetector{MinOccurrences: 2}
+	for _, c := range []Cadence{Weekly, Monthly, Quarterly, Yearly} {
+		act := d.Detect(series("2015-01-15", c, 3), dbapi.Date{})
+		equals(t, 1, len(act))
+		equals(t, c, act[0].Cadence)
+	}
+
+	// Weekly shopping with widely varying amounts isn't recurring.
+	shopping := series("2016-10-01", Weekly, 4)
+	for i, a := range []string{"-35.56", "-12.3", "-80.1", "-8"} {
+		shopping[i].Amount = eur(a)
+	}
+	equals(t, 0, len(DetectRecurring(shopping, dbapi.Date{})))
+
+	// Too few payments with the default settings.
+	equals(t, 0, len(DetectRecurring(series("2015-01-15", Yearly, 2), dbapi.Date{})))
+}
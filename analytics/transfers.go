@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+// A Transfer is a movement of money between two accounts of the user. It
+// consists of two transactions (legs): a debit on the sending account and a
+// credit on the receiving account.
+type Transfer struct {
+	Debit  dbapi.Transaction
+	Credit dbapi.Transaction
+	// DebitFingerprint and CreditFingerprint identify the legs among the
+	// transactions they were matched in (see dbapi.Transactions.Fingerprints),
+	// so they can be told apart from identical transactions.
+	DebitFingerprint  dbapi.Fingerprint
+	CreditFingerprint dbapi.Fingerprint
+}
+
+// From returns the IBAN of the sending account.
+func (t Transfer) From() dbapi.IBAN {
+	return t.Debit.OriginIBAN
+}
+
+// To returns the IBAN of the receiving account.
+func (t Transfer) To() dbapi.IBAN {
+	return t.Credit.OriginIBAN
+}
+
+// Amount returns the transferred amount.
+func (t Transfer) Amount() dbapi.Money {
+	return t.Credit.Amount
+}
+
+// Transfers are transfers between the accounts of the user.
+type Transfers []Transfer
+
+// Contains reports whether the transaction with the fingerprint is a leg of
+// one of the transfers. The fingerprint must stem from the transactions the
+// transfers were matched in (see dbapi.Transactions.Fingerprints).
+func (t Transfers) Contains(fp dbapi.Fingerprint) bool {
+	for _, tr := range t {
+		if tr.DebitFingerprint == fp || tr.CreditFingerprint == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// Exclude returns the transactions which aren't legs of the transfers, in
+// their original order. The transactions must be the ones the transfers were
+// matched in. Transfers should be excluded from an analysis, since they
+// otherwise inflate both income and spending:
+//
+//	report := a.Analyze(transfers.Exclude(transactions))
+//
+// Legs are identified by their fingerprints, so of two identical transactions
+// only the one which is a leg is excluded.
+func (t Transfers) Exclude(txs dbapi.Transactions) dbapi.Transactions {
+	legs := make(map[dbapi.Fingerprint]bool, 2*len(t))
+	for _, tr := range t {
+		legs[tr.DebitFingerprint] = true
+		legs[tr.CreditFingerprint] = true
+	}
+	var r dbapi.Transactions
+	for i, fp := range txs.Fingerprints() {
+		if !legs[fp] {
+			r = append(r, txs[i])
+		}
+	}
+	return r
+}
+
+// Legs returns the debits and credits of the transfers.
+func (t Transfers) Legs() dbapi.Transactions {
+	legs := make(dbapi.Transactions, 0, 2*len(t))
+	for _, tr := range t {
+		legs = append(legs, tr.Debit, tr.Credit)
+	}
+	return legs
+}
+
+// MatchTransfers pairs the debits and credits of transfers between the
+// accounts of the user. A debit and a credit are paired if they are booked on
+// different accounts, have the same absolute amount, are booked at most window
+// days apart and the counterparty IBAN of at least one of them is the IBAN of
+// the other account. The counterparty IBAN of the other one must be the IBAN of
+// the first account or empty. Legs without counterparty IBANs are never
+// paired, since ordinary payments of the same amount would be mistaken for
+// transfers. If a leg could be paired in several ways, the pair whose
+// counterparty IBANs both confirm the match is preferred, then the pair booked
+// closest together.
+//
+// The transfers are ordered by the booking date of their debit.
+func MatchTransfers(accounts dbapi.Accounts, t dbapi.Transactions, window int) Transfers {
+	own := make(map[dbapi.IBAN]bool, len(accounts))
+	for _, acc := range accounts {
+		own[acc.Iban] = true
+	}
+	fps := t.Fingerprints()
+	var debits, credits []int
+	for i, tx := range t {
+		switch {
+		case !own[tx.OriginIBAN]:
+		case tx.IsDebit():
+			debits = append(debits, i)
+		case tx.IsCredit():
+			credits = append(credits, i)
+		}
+	}
+
+	type candidate struct {
+		debit, credit int
+		// confirmed is the number of legs whose counterparty IBAN is the IBAN
+		// of the other leg.
+		confirmed int
+		days      int
+	}
+	var candidates []candidate
+	for _, di := range debits {
+		d := t[di]
+		for _, ci := range credits {
+			c := t[ci]
+			days := abs(c.BookingDate.DaysSince(d.BookingDate))
+			switch {
+			case c.OriginIBAN == d.OriginIBAN,
+				!c.Amount.Equal(d.Amount.Neg()),
+				days > window,
+				d.CounterPartyIBAN != "" && d.CounterPartyIBAN != c.OriginIBAN,
+				c.CounterPartyIBAN != "" && c.CounterPartyIBAN != d.OriginIBAN:
+				continue
+			}
+			confirmed := 0
+			if d.CounterPartyIBAN != "" {
+				confirmed++
+			}
+			if c.CounterPartyIBAN != "" {
+				confirmed++
+			}
+			if confirmed == 0 {
+				continue
+			}
+			candidates = append(candidates, candidate{di, ci, confirmed, days})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].confirmed != candidates[j].confirmed {
+			return candidates[i].confirmed > candidates[j].confirmed
+		}
+		return candidates[i].days < candidates[j].days
+	})
+
+	var r Transfers
+	used := make(map[int]bool)
+	for _, c := range candidates {
+		if used[c.debit] || used[c.credit] {
+			continue
+		}
+		used[c.debit], used[c.credit] = true, true
+		r = append(r, Transfer{
+			Debit:             t[c.debit],
+			Credit:            t[c.credit],
+			DebitFingerprint:  fps[c.debit],
+			CreditFingerprint: fps[c.credit],
+		})
+	}
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Debit.BookingDate.Before(r[j].Debit.BookingDate)
+	})
+	return r
+}
@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/lukasmalkmus/dbapi"
+)
+
+func TestMatchTransfers(t *testing.T) {
+	const (
+		giro    = "DE10000000000000000454"
+		savings = "DE10000000000000000455"
+		foreign = "DE89370400440532013000"
+	)
+	accounts := dbapi.Accounts{{Iban: giro}, {Iban: savings}}
+	txs := dbapi.Transactions{
+		// Transfer confirmed by the counterparty IBANs, booked a day apart.
+		{OriginIBAN: giro, Amount: eur("-50"), CounterPartyIBAN: savings, Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: savings, Amount: eur("50"), CounterPartyIBAN: giro, Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-10-02")},
+		// A credit of the same amount from somebody else.
+		{OriginIBAN: savings, Amount: eur("50"), CounterPartyIBAN: foreign, BookingDate: mustParseDate("2016-10-01")},
+		// Transfer confirmed by the counterparty IBAN of one leg.
+		{OriginIBAN: savings, Amount: eur("-100"), CounterPartyIBAN: giro, BookingDate: mustParseDate("2016-09-15")},
+		{OriginIBAN: giro, Amount: eur("100"), BookingDate: mustParseDate("2016-09-15")},
+		// Too far apart.
+		{OriginIBAN: giro, Amount: eur("-20"), BookingDate: mustParseDate("2016-08-01")},
+		{OriginIBAN: savings, Amount: eur("20"), BookingDate: mustParseDate("2016-08-10")},
+		// Same account.
+		{OriginIBAN: giro, Amount: eur("-30"), BookingDate: mustParseDate("2016-07-01")},
+		{OriginIBAN: giro, Amount: eur("30"), BookingDate: mustParseDate("2016-07-01")},
+		// Payment to somebody else.
+		{OriginIBAN: giro, Amount: eur("-1500"), CounterPartyIBAN: foreign, BookingDate: mustParseDate("2016-10-21")},
+		// Payment and income without counterparty IBANs.
+		{OriginIBAN: giro, Amount: eur("-35.56"), CounterPartyName: "Netto", BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: savings, Amount: eur("35.56"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-10-27")},
+	}
+
+	act := MatchTransfers(accounts, txs, 3)
+
+	fps := txs.Fingerprints()
+	equals(t, Transfers{
+		{Debit: txs[3], Credit: txs[4], DebitFingerprint: fps[3], CreditFingerprint: fps[4]},
+		{Debit: txs[0], Credit: txs[1], DebitFingerprint: fps[0], CreditFingerprint: fps[1]},
+	}, act)
+	equals(t, dbapi.IBAN(giro), act[1].From())
+	equals(t, dbapi.IBAN(savings), act[1].To())
+	equals(t, eur("50"), act[1].Amount())
+
+	assert(t, act.Contains(fps[1]), "expected credit leg to be contained")
+	assert(t, !act.Contains(fps[2]), "expected foreign credit not to be contained")
+	equals(t, dbapi.Transactions{txs[3], txs[4], txs[0], txs[1]}, act.Legs())
+
+	// Transfers can be excluded from analytics.
+	r := (&Analyzer{}).Analyze(act.Exclude(txs))
+	equals(t, 8, r.Total.Count)
+}
+
+func TestTransfers_Exclude(t *testing.T) {
+	const (
+		giro    = "DE10000000000000000454"
+		savings = "DE10000000000000000455"
+	)
+	accounts := dbapi.Accounts{{Iban: giro}, {Iban: savings}}
+	// Two identical credits, of which only one is the leg of a transfer.
+	txs := dbapi.Transactions{
+		{OriginIBAN: giro, Amount: eur("-25"), CounterPartyIBAN: savings, BookingDate: mustParseDate("2016-10-05")},
+		{OriginIBAN: savings, Amount: eur("25"), CounterPartyIBAN: giro, BookingDate: mustParseDate("2016-10-05")},
+		{OriginIBAN: savings, Amount: eur("25"), CounterPartyIBAN: giro, BookingDate: mustParseDate("2016-10-05")},
+	}
+
+	transfers := MatchTransfers(accounts, txs, 3)
+	equals(t, 1, len(transfers))
+	equals(t, dbapi.Transactions{txs[2]}, transfers.Exclude(txs))
+}
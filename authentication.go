@@ -1,17 +1,461 @@
 package dbapi
 
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuthURL is the URL of the authorization endpoint of the Deutsche
+	// Bank API which is used by default.
+	DefaultAuthURL = "https://simulator-api.db.com/gw/oidc/authorize"
+	// DefaultTokenURL is the URL of the token endpoint of the Deutsche Bank API
+	// which is used by default.
+	DefaultTokenURL = "https://simulator-api.db.com/gw/oidc/token"
+)
+
+var (
+	// ErrInvalidOAuth2Config is raised when the OAuth2 configuration is invalid
+	// (e.g. the client ID is missing).
+	ErrInvalidOAuth2Config = errors.New("Invalid OAuth2 configuration")
+	// ErrInvalidPKCE is raised when the PKCE parameters are missing or invalid.
+	ErrInvalidPKCE = errors.New("Invalid PKCE parameters")
+	// ErrInvalidTokenSource is raised when the token source is invalid (e.g.
+	// nil).
+	ErrInvalidTokenSource = errors.New("Invalid token source")
+	// ErrNoRefreshToken is raised when a token needs to be refreshed but no
+	// refresh token is available.
+	ErrNoRefreshToken = errors.New("No refresh token available")
+)
+
+// expiryDelta is the time before its expiry at which a token is considered
+// expired and is refreshed.
+const expiryDelta = 30 * time.Second
+
 // The AuthenticationService is a simple wrapper around the authentication
-// credentials/tokens.
+// credentials/tokens. It also implements the OAuth2 authorization code flow
+// with PKCE (RFC 7636) to obtain them.
 type AuthenticationService struct {
-	token string
+	client *Client
+
+	mu          sync.RWMutex
+	token       string
+	source      TokenSource
+	config      OAuth2Config
+	oauth2Token *Token
+	store       TokenStore
+	storeKey    string
+}
+
+// A TokenSource supplies the token which is used to authenticate a request. It
+// is asked for a token before every request and must be safe for concurrent
+// use.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// OAuth2Config is the configuration of the OAuth2 client which is registered as
+// application on the developer portal.
+type OAuth2Config struct {
+	// ClientID is the ID of the application.
+	ClientID string
+	// ClientSecret is the secret of the application. It is optional since
+	// public clients authenticate by using PKCE only.
+	ClientSecret string
+	// RedirectURL is the URL the user is redirected to after authorization.
+	RedirectURL string
+	// Scopes are the scopes to request (e.g. read_accounts).
+	Scopes []string
+	// AuthURL is the URL of the authorization endpoint. DefaultAuthURL is used
+	// if empty.
+	AuthURL string
+	// TokenURL is the URL of the token endpoint. DefaultTokenURL is used if
+	// empty.
+	TokenURL string
+}
+
+// A Token holds the credentials returned by the token endpoint.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Valid reports whether the token has an access token which doesn't expire
+// within the next seconds.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// PKCE holds the parameters of the Proof Key for Code Exchange (RFC 7636). The
+// Verifier must be kept secret until the authorization code is exchanged.
+type PKCE struct {
+	Verifier        string
+	Challenge       string
+	ChallengeMethod string
+}
+
+// A TokenError is returned if the token endpoint rejects a request.
+type TokenError struct {
+	// Response is the HTTP response that caused this error.
+	Response *http.Response `json:"-"`
+
+	// Code is the OAuth2 error code (e.g. invalid_grant).
+	Code string `json:"error"`
+	// Description is a human readable description of the error, if any.
+	Description string `json:"error_description,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *TokenError) Error() string {
+	msg := fmt.Sprintf("Token request to %s failed: %s", e.Response.Request.URL.String(), e.Response.Status)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Description != "" {
+		msg += " (" + e.Description + ")"
+	}
+	return msg
+}
+
+// SetOAuth2Config specifies the OAuth2 configuration which is used to obtain
+// tokens. An error ErrInvalidOAuth2Config is returned if the client ID or the
+// redirect URL are missing.
+func SetOAuth2Config(config OAuth2Config) Option {
+	return func(c *Client) error { return c.setOAuth2Config(config) }
+}
+func (c *Client) setOAuth2Config(config OAuth2Config) error {
+	if config.ClientID == "" || config.RedirectURL == "" {
+		return ErrInvalidOAuth2Config
+	}
+	if config.AuthURL == "" {
+		config.AuthURL = DefaultAuthURL
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = DefaultTokenURL
+	}
+	c.Authentication.config = config
+	return nil
+}
+
+// SetTokenSource specifies the source of the tokens which are used to
+// authenticate requests. It replaces a token set by SetToken. An error
+// ErrInvalidTokenSource is returned if the passed source is nil.
+func SetTokenSource(source TokenSource) Option {
+	return func(c *Client) error { return c.setTokenSource(source) }
+}
+func (c *Client) setTokenSource(source TokenSource) error {
+	if source == nil {
+		return ErrInvalidTokenSource
+	}
+	s := c.Authentication
+	s.mu.Lock()
+	s.token, s.source = "", source
+	s.mu.Unlock()
+	return nil
 }
 
 // HasAuth describes if authentication credentials are set.
 func (s *AuthenticationService) HasAuth() bool {
-	return len(s.token) > 0
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.source != nil
 }
 
-// Token returns the access token.
+// Token returns the access token. If the token is provided by a TokenSource,
+// it is the access token which has been used for the most recent request.
 func (s *AuthenticationService) Token() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.token
 }
+
+// TokenSource returns the source of the tokens which are used to authenticate
+// requests. It is nil if no authentication credentials are set.
+func (s *AuthenticationService) TokenSource() TokenSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.source
+}
+
+// OAuth2Token returns the token obtained by Exchange or the latest refreshed
+// one. It is nil if no token has been obtained, yet.
+func (s *AuthenticationService) OAuth2Token() *Token {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.oauth2Token
+}
+
+// authorize fetches a token from the source and remembers its access token.
+func (s *AuthenticationService) authorize(ctx context.Context, source TokenSource) (*Token, error) {
+	tok, err := source.Token(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	if tok == nil || tok.AccessToken == "" {
+		return nil, ErrInvalidTokenSource
+	}
+	s.mu.Lock()
+	s.token = tok.AccessToken
+	s.mu.Unlock()
+	return tok, nil
+}
+
+// RefreshTokenSource returns a TokenSource which starts with the token tok and
+// refreshes it by using its refresh token shortly before it expires. Concurrent
+// refreshes are serialized, so the token endpoint is called only once.
+func (s *AuthenticationService) RefreshTokenSource(tok *Token) TokenSource {
+	return &refreshTokenSource{s: s, tok: tok}
+}
+
+// StaticTokenSource returns a TokenSource which always returns the access token
+// token. It never expires.
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource{&Token{AccessToken: token, TokenType: "Bearer"}}
+}
+
+type staticTokenSource struct {
+	tok *Token
+}
+
+func (s staticTokenSource) Token(ctx context.Context) (*Token, error) {
+	return s.tok, nil
+}
+
+type refreshTokenSource struct {
+	s *AuthenticationService
+
+	mu  sync.Mutex
+	tok *Token
+}
+
+func (r *refreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tok.Valid() {
+		return r.tok, nil
+	}
+	if r.tok == nil || r.tok.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	// A token which couldn't be persisted in the token store is still used, the
+	// next refresh tries to persist it again.
+	tok, err := r.s.RefreshContext(ctx, r.tok)
+	if tok == nil {
+		return nil, err
+	}
+	r.tok = tok
+	return tok, nil
+}
+
+// invalidate marks the token tok as expired if it is still the current one, so
+// the next call to Token refreshes it. It reports whether a refresh is
+// possible.
+func (r *refreshTokenSource) invalidate(tok *Token) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tok == tok && tok != nil {
+		expired := *tok
+		expired.Expiry = time.Unix(1, 0)
+		r.tok = &expired
+	}
+	return r.tok != nil && r.tok.RefreshToken != ""
+}
+
+// Refresh obtains a new token by using the refresh token of tok. The refresh
+// token is kept if the token endpoint doesn't return a new one. The new token is
+// persisted in the token store, if present. It is returned along with the error
+// if that fails.
+func (s *AuthenticationService) Refresh(tok *Token) (*Token, error) {
+	return s.RefreshContext(context.Background(), tok)
+}
+
+// RefreshContext is like Refresh but uses the context ctx for the request.
+func (s *AuthenticationService) RefreshContext(ctx context.Context, tok *Token) (*Token, error) {
+	if tok == nil || tok.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	v := url.Values{}
+	v.Set("grant_type", "refresh_token")
+	v.Set("refresh_token", tok.RefreshToken)
+
+	newTok, err := s.requestToken(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if newTok.RefreshToken == "" {
+		newTok.RefreshToken = tok.RefreshToken
+	}
+	s.mu.Lock()
+	s.oauth2Token = newTok
+	s.mu.Unlock()
+	return newTok, s.saveToken(newTok)
+}
+
+// NewPKCE generates new random PKCE parameters which use the S256 challenge
+// method. They should be used for exactly one authorization.
+func NewPKCE() (*PKCE, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:        verifier,
+		Challenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		ChallengeMethod: "S256",
+	}, nil
+}
+
+// AuthorizeURL returns the URL of the authorization endpoint the user must be
+// redirected to. The state is an opaque value which is passed back to the
+// redirect URL and should be used to protect against CSRF attacks.
+func (s *AuthenticationService) AuthorizeURL(state string, pkce *PKCE) (string, error) {
+	if s.config.ClientID == "" {
+		return "", ErrInvalidOAuth2Config
+	}
+	if pkce == nil || pkce.Challenge == "" {
+		return "", ErrInvalidPKCE
+	}
+	u, err := url.Parse(s.config.AuthURL)
+	if err != nil {
+		return "", ErrInvalidOAuth2Config
+	}
+
+	v := u.Query()
+	v.Set("response_type", "code")
+	v.Set("client_id", s.config.ClientID)
+	v.Set("redirect_uri", s.config.RedirectURL)
+	if len(s.config.Scopes) > 0 {
+		v.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if state != "" {
+		v.Set("state", state)
+	}
+	v.Set("code_challenge", pkce.Challenge)
+	v.Set("code_challenge_method", pkce.ChallengeMethod)
+	u.RawQuery = v.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange exchanges the authorization code for a token. The PKCE parameters
+// must be the same ones that have been used to build the authorize URL. On
+// success the token is stored and used to authenticate subsequent requests. It
+// is refreshed transparently as long as the token endpoint returns a refresh
+// token. The token is persisted in the token store, if present. It is returned
+// along with the error if that fails.
+func (s *AuthenticationService) Exchange(code string, pkce *PKCE) (*Token, error) {
+	return s.ExchangeContext(context.Background(), code, pkce)
+}
+
+// ExchangeContext is like Exchange but uses the context ctx for the request.
+func (s *AuthenticationService) ExchangeContext(ctx context.Context, code string, pkce *PKCE) (*Token, error) {
+	if pkce == nil || pkce.Verifier == "" {
+		return nil, ErrInvalidPKCE
+	}
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", s.config.RedirectURL)
+	v.Set("code_verifier", pkce.Verifier)
+
+	tok, err := s.requestToken(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.token, s.oauth2Token = tok.AccessToken, tok
+	s.source = s.RefreshTokenSource(tok)
+	s.mu.Unlock()
+	return tok, s.saveToken(tok)
+}
+
+// requestToken posts the values v to the token endpoint and decodes the token
+// from the response.
+func (s *AuthenticationService) requestToken(ctx context.Context, v url.Values) (*Token, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if s.config.ClientID == "" {
+		return nil, ErrInvalidOAuth2Config
+	}
+	v.Set("client_id", s.config.ClientID)
+
+	req, err := http.NewRequest(http.MethodPost, s.config.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "dbapi/"+version)
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	resp, err := s.client.client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if c := resp.StatusCode; c < 200 || c > 299 {
+		tokenErr := &TokenError{Response: resp}
+		json.Unmarshal(body, tokenErr)
+		return nil, tokenErr
+	}
+
+	var raw struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		Scope        string `json:"scope"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	if raw.AccessToken == "" {
+		return nil, &TokenError{Response: resp, Code: "invalid_response", Description: "missing access token"}
+	}
+
+	tok := &Token{
+		AccessToken:  raw.AccessToken,
+		TokenType:    raw.TokenType,
+		RefreshToken: raw.RefreshToken,
+		Scope:        raw.Scope,
+	}
+	if raw.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(raw.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
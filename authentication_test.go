@@ -1,6 +1,17 @@
 package dbapi
 
-import "testing"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestHasAuth(t *testing.T) {
 	mockData := []struct {
@@ -45,3 +56,287 @@ func TestToken(t *testing.T) {
 		equals(t, err, mock.ExpectedError)
 	}
 }
+
+func TestSetOAuth2Config(t *testing.T) {
+	mockData := []struct {
+		Config         OAuth2Config
+		ExpectedConfig OAuth2Config
+		ExpectedError  error
+	}{
+		{
+			OAuth2Config{ClientID: "id", RedirectURL: "http://localhost/cb"},
+			OAuth2Config{ClientID: "id", RedirectURL: "http://localhost/cb", AuthURL: DefaultAuthURL, TokenURL: DefaultTokenURL},
+			nil,
+		},
+		{
+			OAuth2Config{ClientID: "id", RedirectURL: "http://localhost/cb", AuthURL: "http://auth", TokenURL: "http://token"},
+			OAuth2Config{ClientID: "id", RedirectURL: "http://localhost/cb", AuthURL: "http://auth", TokenURL: "http://token"},
+			nil,
+		},
+		{OAuth2Config{ClientID: "id"}, OAuth2Config{}, ErrInvalidOAuth2Config},
+		{OAuth2Config{RedirectURL: "http://localhost/cb"}, OAuth2Config{}, ErrInvalidOAuth2Config},
+	}
+
+	for _, mock := range mockData {
+		c, err := NewClient(
+			SetOAuth2Config(mock.Config),
+		)
+		if c != nil {
+			equals(t, mock.ExpectedConfig, c.Authentication.config)
+		}
+		equals(t, mock.ExpectedError, err)
+	}
+}
+
+func TestNewPKCE(t *testing.T) {
+	p, err := NewPKCE()
+	ok(t, err)
+
+	sum := sha256.Sum256([]byte(p.Verifier))
+	equals(t, base64.RawURLEncoding.EncodeToString(sum[:]), p.Challenge)
+	equals(t, "S256", p.ChallengeMethod)
+	equals(t, 43, len(p.Verifier))
+
+	q, err := NewPKCE()
+	ok(t, err)
+	assert(t, p.Verifier != q.Verifier, "Expected verifiers to be random.")
+}
+
+func TestAuthenticationService_AuthorizeURL(t *testing.T) {
+	c, err := NewClient(
+		SetOAuth2Config(OAuth2Config{
+			ClientID:    "client",
+			RedirectURL: "http://localhost/callback",
+			Scopes:      []string{"read_accounts", "read_transactions"},
+			AuthURL:     "https://auth.example.com/authorize?prompt=login",
+		}),
+	)
+	ok(t, err)
+
+	pkce := &PKCE{Verifier: "verifier", Challenge: "challenge", ChallengeMethod: "S256"}
+	authURL, err := c.Authentication.AuthorizeURL("xyz", pkce)
+	ok(t, err)
+
+	u, err := url.Parse(authURL)
+	ok(t, err)
+	equals(t, "auth.example.com", u.Host)
+	equals(t, url.Values{
+		"prompt":                {"login"},
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"redirect_uri":          {"http://localhost/callback"},
+		"scope":                 {"read_accounts read_transactions"},
+		"state":                 {"xyz"},
+		"code_challenge":        {"challenge"},
+		"code_challenge_method": {"S256"},
+	}, u.Query())
+
+	_, err = c.Authentication.AuthorizeURL("xyz", nil)
+	equals(t, ErrInvalidPKCE, err)
+}
+
+func TestAuthenticationService_Exchange(t *testing.T) {
+	setupAuth()
+	defer teardown()
+
+	pkce, err := NewPKCE()
+	ok(t, err)
+	testAuthServer.challenge = pkce.Challenge
+
+	tok, err := testClient.Authentication.Exchange(testAuthCode, pkce)
+	ok(t, err)
+	equals(t, "access-1", tok.AccessToken)
+	equals(t, "refresh-1", tok.RefreshToken)
+	equals(t, "Bearer", tok.TokenType)
+	assert(t, time.Until(tok.Expiry) > 50*time.Minute, "Expected token to expire in about an hour, got %s.", tok.Expiry)
+
+	// The token is used for subsequent requests.
+	equals(t, tok, testClient.Authentication.OAuth2Token())
+	equals(t, "access-1", testClient.Authentication.Token())
+}
+
+func TestAuthenticationService_Exchange_Error(t *testing.T) {
+	setupAuth()
+	defer teardown()
+
+	pkce, err := NewPKCE()
+	ok(t, err)
+	testAuthServer.challenge = "other-challenge"
+
+	_, err = testClient.Authentication.Exchange(testAuthCode, pkce)
+	tokenErr, isTokenErr := err.(*TokenError)
+	assert(t, isTokenErr, "Expected *TokenError, got %#v.", err)
+	equals(t, "invalid_grant", tokenErr.Code)
+	equals(t, http.StatusBadRequest, tokenErr.Response.StatusCode)
+	equals(t, testAccessToken, testClient.Authentication.Token())
+}
+
+const (
+	// testAuthCode is the authorization code accepted by the test auth server.
+	testAuthCode = "auth-code"
+)
+
+// testAuthServer is a stand-in for the authorization server of the Deutsche
+// Bank API. It is configured by setupAuth.
+var testAuthServer *authServer
+
+// authServer implements the token endpoint of an OAuth2 authorization server
+// which supports the authorization code grant with PKCE and the refresh token
+// grant.
+type authServer struct {
+	challenge string
+	issued    int
+}
+
+func (s *authServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != testAuthCode || base64.RawURLEncoding.EncodeToString(sum[:]) != s.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"code verifier mismatch"}`)
+			return
+		}
+	case "refresh_token":
+		if r.PostForm.Get("refresh_token") != fmt.Sprintf("refresh-%d", s.issued) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"unsupported_grant_type"}`)
+		return
+	}
+
+	s.issued++
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","refresh_token":"refresh-%d","expires_in":3600}`, s.issued, s.issued)
+}
+
+// setupAuth is like setup but also serves a stand-in authorization server at
+// /oauth/token and configures the client to use it.
+func setupAuth() {
+	setup()
+
+	testAuthServer = &authServer{}
+	testMux.Handle("/oauth/token", testAuthServer)
+	err := testClient.Options(SetOAuth2Config(OAuth2Config{
+		ClientID:    "client",
+		RedirectURL: "http://localhost/callback",
+		AuthURL:     testServer.URL + "/oauth/authorize",
+		TokenURL:    testServer.URL + "/oauth/token",
+	}))
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestSetTokenSource(t *testing.T) {
+	source := StaticTokenSource(testAccessToken)
+
+	mockData := []struct {
+		Source        TokenSource
+		ExpectedAuth  bool
+		ExpectedError error
+	}{
+		{source, true, nil},
+		{nil, false, ErrInvalidTokenSource},
+	}
+
+	for _, mock := range mockData {
+		c, err := NewClient(
+			SetTokenSource(mock.Source),
+		)
+		if c != nil {
+			equals(t, mock.Source, c.Authentication.TokenSource())
+			equals(t, mock.ExpectedAuth, c.Authentication.HasAuth())
+		}
+		equals(t, mock.ExpectedError, err)
+	}
+}
+
+func TestToken_Valid(t *testing.T) {
+	mockData := []struct {
+		Token         *Token
+		ExpectedValid bool
+	}{
+		{nil, false},
+		{&Token{}, false},
+		{&Token{AccessToken: "a"}, true},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, true},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(time.Second)}, false},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(-time.Hour)}, false},
+	}
+
+	for _, mock := range mockData {
+		equals(t, mock.ExpectedValid, mock.Token.Valid())
+	}
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	setupAuth()
+	defer teardown()
+
+	testAuthServer.issued = 1
+	expired := &Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Minute)}
+	source := testClient.Authentication.RefreshTokenSource(expired)
+
+	// Concurrent callers share a single refresh.
+	var wg sync.WaitGroup
+	toks := make([]*Token, 10)
+	for i := range toks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			toks[i], _ = source.Token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	equals(t, 2, testAuthServer.issued)
+	for _, tok := range toks {
+		equals(t, "access-2", tok.AccessToken)
+		equals(t, "refresh-2", tok.RefreshToken)
+	}
+	equals(t, toks[0], testClient.Authentication.OAuth2Token())
+
+	_, err := testClient.Authentication.RefreshTokenSource(&Token{}).Token(context.Background())
+	equals(t, ErrNoRefreshToken, err)
+}
+
+func TestAuthMiddleware_RefreshOnUnauthorized(t *testing.T) {
+	setupAuth()
+	defer teardown()
+
+	testAuthServer.issued = 1
+	revoked := &Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(time.Hour)}
+	ok(t, testClient.Options(SetTokenSource(testClient.Authentication.RefreshTokenSource(revoked))))
+
+	var auths []string
+	testMux.HandleFunc("/v1/foo", func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer access-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, err := testClient.Call(http.MethodGet, "/foo", nil, nil)
+	ok(t, err)
+	equals(t, []string{"Bearer access-1", "Bearer access-2"}, auths)
+	equals(t, "access-2", testClient.Authentication.Token())
+
+	// A static token isn't retried.
+	auths = nil
+	ok(t, testClient.Options(SetToken("static")))
+	_, err = testClient.Call(http.MethodGet, "/foo", nil, nil)
+	assert(t, errors.Is(err, ErrUnauthorized), "Expected error to match ErrUnauthorized, got %v.", err)
+	equals(t, []string{"Bearer static"}, auths)
+}
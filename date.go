@@ -0,0 +1,171 @@
+package dbapi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidDate is raised when a date can't be parsed or doesn't exist (e.g.
+// February 30).
+var ErrInvalidDate = errors.New("Invalid date")
+
+// dateLayout is the layout of dates used by the API (ISO 8601).
+const dateLayout = "2006-01-02"
+
+// A Date is a civil date (year, month and day) without time and time zone, like
+// a booking date or a date of birth. The zero value is the zero date, which
+// represents an unknown date.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date of the year, month and day. Like time.Date, values
+// outside their usual ranges are normalized (e.g. October 32 becomes
+// November 1).
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date on which the time t occurs in its location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// Today returns the current date in the location loc.
+func Today(loc *time.Location) Date {
+	return DateOf(time.Now().In(loc))
+}
+
+// ParseDate parses a date in ISO 8601 format (e.g. "2016-10-27"). An error
+// wrapping ErrInvalidDate is returned if the date is malformed or doesn't exist.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("%w %q", ErrInvalidDate, s)
+	}
+	return DateOf(t), nil
+}
+
+// String returns the date in ISO 8601 format (e.g. "2016-10-27"). The zero date
+// is returned as empty string.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether the date is the zero date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// IsValid reports whether the date exists.
+func (d Date) IsValid() bool {
+	return DateOf(d.In(time.UTC)) == d
+}
+
+// In returns the time at the start of the date in the location loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// Weekday returns the day of the week of the date.
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+// AddDays returns the date n days after d. n may be negative.
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// AddDate returns the date which results from adding years, months and days to
+// d. It normalizes its result like time.Time.AddDate, so adding one month to
+// October 31 gives December 1. Use AddMonths to stay within the month.
+func (d Date) AddDate(years, months, days int) Date {
+	return NewDate(d.Year+years, d.Month+time.Month(months), d.Day+days)
+}
+
+// AddMonths returns the date n months after d. If the day doesn't exist in the
+// resulting month, the last day of that month is returned, so adding one month
+// to January 31 gives February 28 (or 29).
+func (d Date) AddMonths(n int) Date {
+	first := NewDate(d.Year, d.Month+time.Month(n), 1)
+	if last := first.DaysInMonth(); d.Day > last {
+		return Date{Year: first.Year, Month: first.Month, Day: last}
+	}
+	return Date{Year: first.Year, Month: first.Month, Day: d.Day}
+}
+
+// DaysInMonth returns the number of days of the month of the date.
+func (d Date) DaysInMonth() int {
+	return NewDate(d.Year, d.Month+1, 0).Day
+}
+
+// DaysSince returns the number of days from the date o to d. It is negative if o
+// is after d.
+func (d Date) DaysSince(o Date) int {
+	// Both times are in UTC, so there are no daylight saving time transitions
+	// and every day has exactly 24 hours.
+	return int(d.In(time.UTC).Sub(o.In(time.UTC)) / (24 * time.Hour))
+}
+
+// Before reports whether the date d is before o.
+func (d Date) Before(o Date) bool {
+	return d.Compare(o) < 0
+}
+
+// After reports whether the date d is after o.
+func (d Date) After(o Date) bool {
+	return d.Compare(o) > 0
+}
+
+// Compare compares the dates d and o and returns -1 if d is before o, 0 if they
+// are equal and +1 if d is after o.
+func (d Date) Compare(o Date) int {
+	switch {
+	case d.Year != o.Year:
+		return cmpInt(d.Year, o.Year)
+	case d.Month != o.Month:
+		return cmpInt(int(d.Month), int(o.Month))
+	}
+	return cmpInt(d.Day, o.Day)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The date is
+// encoded in ISO 8601 format, the zero date as empty string.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. An empty
+// string is decoded as the zero date.
+func (d *Date) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*d = Date{}
+		return nil
+	}
+	date, err := ParseDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// cmpInt returns -1, 0 or +1 depending on whether a is less than, equal to or
+// greater than b.
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
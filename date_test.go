@@ -0,0 +1,101 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	mockData := []struct {
+		Date          string
+		ExpectedDate  Date
+		ExpectedError error
+	}{
+		{"2016-10-27", Date{2016, time.October, 27}, nil},
+		{"2016-02-29", Date{2016, time.February, 29}, nil},
+		{"2017-02-29", Date{}, ErrInvalidDate},
+		{"27.10.2016", Date{}, ErrInvalidDate},
+		{"2016-10-27T12:00:00Z", Date{}, ErrInvalidDate},
+		{"", Date{}, ErrInvalidDate},
+	}
+
+	for _, mock := range mockData {
+		d, err := ParseDate(mock.Date)
+		equals(t, mock.ExpectedDate, d)
+		assert(t, errors.Is(err, mock.ExpectedError), "Expected %v, got %v.", mock.ExpectedError, err)
+	}
+}
+
+func TestDate_Arithmetic(t *testing.T) {
+	d := mustParseDate("2016-10-27")
+
+	equals(t, mustParseDate("2016-11-03"), d.AddDays(7))
+	equals(t, mustParseDate("2016-09-30"), d.AddDays(-27))
+	equals(t, mustParseDate("2017-12-28"), d.AddDate(1, 2, 1))
+	equals(t, mustParseDate("2016-02-29"), mustParseDate("2016-01-31").AddMonths(1))
+	equals(t, mustParseDate("2015-11-30"), mustParseDate("2016-01-31").AddMonths(-2))
+	equals(t, 29, mustParseDate("2016-02-10").DaysInMonth())
+	equals(t, 26, d.DaysSince(mustParseDate("2016-10-01")))
+	equals(t, -366, mustParseDate("2016-01-01").DaysSince(mustParseDate("2017-01-01")))
+	equals(t, time.Thursday, d.Weekday())
+
+	// Days are counted in civil time, regardless of daylight saving time.
+	equals(t, 1, mustParseDate("2016-10-31").DaysSince(mustParseDate("2016-10-30")))
+}
+
+func TestDate_Compare(t *testing.T) {
+	a, b := mustParseDate("2016-09-30"), mustParseDate("2016-10-01")
+
+	assert(t, a.Before(b), "Expected %s to be before %s.", a, b)
+	assert(t, b.After(a), "Expected %s to be after %s.", b, a)
+	equals(t, 0, a.Compare(a))
+
+	dates := []Date{mustParseDate("2016-10-27"), mustParseDate("2015-12-31"), mustParseDate("2016-01-02"), mustParseDate("2016-10-12")}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	equals(t, []Date{mustParseDate("2015-12-31"), mustParseDate("2016-01-02"), mustParseDate("2016-10-12"), mustParseDate("2016-10-27")}, dates)
+}
+
+func TestDate_Time(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("Time zone database not available.")
+	}
+	d := mustParseDate("2016-10-27")
+
+	equals(t, time.Date(2016, time.October, 27, 0, 0, 0, 0, berlin), d.In(berlin))
+	// Shortly after midnight in Berlin it is still the previous day in UTC.
+	midnight := time.Date(2016, time.October, 28, 0, 30, 0, 0, berlin)
+	equals(t, d.AddDays(1), DateOf(midnight))
+	equals(t, d, DateOf(midnight.UTC()))
+}
+
+func TestDate_JSON(t *testing.T) {
+	var v struct {
+		Date Date `json:"date"`
+	}
+	ok(t, json.Unmarshal([]byte(`{"date":"1977-03-02"}`), &v))
+	equals(t, Date{1977, time.March, 2}, v.Date)
+
+	b, err := json.Marshal(v)
+	ok(t, err)
+	equals(t, `{"date":"1977-03-02"}`, string(b))
+
+	ok(t, json.Unmarshal([]byte(`{"date":""}`), &v))
+	assert(t, v.Date.IsZero(), "Expected zero date, got %s.", v.Date)
+
+	err = json.Unmarshal([]byte(`{"date":"02.03.1977"}`), &v)
+	assert(t, errors.Is(err, ErrInvalidDate), "Expected ErrInvalidDate, got %v.", err)
+}
+
+// mustParseDate parses the date in ISO 8601 format. It panics if the date is
+// invalid.
+func mustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
@@ -2,10 +2,12 @@ package dbapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -31,28 +33,61 @@ var (
 	ErrInvalidClient = errors.New("Invalid http client")
 	// ErrInvalidURL is raised when the url couldn't be parsed by url.Parse().
 	ErrInvalidURL = errors.New("Invalid url")
+	// ErrNilContext is raised when a nil context is passed to one of the
+	// context aware methods.
+	ErrNilContext = errors.New("Context must be non-nil")
+	// ErrInvalidMiddleware is raised when a middleware is invalid (e.g. nil).
+	ErrInvalidMiddleware = errors.New("Invalid middleware")
+)
+
+var (
+	// ErrUnauthorized is matched by an ErrorResponse with status code 401. The
+	// access token is missing, invalid or has expired.
+	ErrUnauthorized = errors.New("Unauthorized")
+	// ErrForbidden is matched by an ErrorResponse with status code 403. Usually
+	// the user didn't grant the scope which is required by the endpoint.
+	ErrForbidden = errors.New("Forbidden or insufficient scope")
+	// ErrNotFound is matched by an ErrorResponse with status code 404.
+	ErrNotFound = errors.New("Not found")
+	// ErrRateLimited is matched by an ErrorResponse with status code 429.
+	ErrRateLimited = errors.New("Rate limited")
+	// ErrServerError is matched by an ErrorResponse with a status code in the
+	// 500 range.
+	ErrServerError = errors.New("Server error")
 )
 
 // A Client manages communication with the Deutsche Bank API.
 type Client struct {
-	client  *http.Client
-	baseURL *url.URL
-	version Version
+	client      *http.Client
+	baseURL     *url.URL
+	version     Version
+	retryPolicy RetryPolicy
+	middleware  []Middleware
 
 	// Authentication
 	Authentication *AuthenticationService
 
 	// API Resources
-	Addresses    *AddressesService
-	Accounts     *AccountsService
-	Transactions *TransactionsService
-	UserInfo     *UserInfoService
+	Addresses        *AddressesService
+	Accounts         *AccountsService
+	Partners         *PartnersService
+	ProcessingOrders *ProcessingOrdersService
+	Transactions     *TransactionsService
+
+	// Deprecated: Use Partners instead.
+	UserInfo *UserInfoService
 }
 
 // A Response represents a http response from the Deutsche Bank API. It is a
-// wrapper around the standard http.Response type.
+// wrapper around the standard http.Response type. If the request failed without
+// a response (e.g. because of a network error), the embedded http.Response is
+// nil, but Attempts still lists the failed attempts.
 type Response struct {
 	*http.Response
+
+	// Attempts lists every attempt that has been made to send the request. It
+	// contains more than one element only if the request has been retried.
+	Attempts []Attempt
 }
 
 // Version is the API version.
@@ -79,12 +114,19 @@ func (c *Client) setClient(client *http.Client) error {
 	return nil
 }
 
-// SetToken specifies the api token.
+// SetToken specifies the api token. It is a shorthand for SetTokenSource with a
+// StaticTokenSource. An empty token removes the authentication credentials.
 func SetToken(token string) Option {
 	return func(c *Client) error { return c.setToken(token) }
 }
 func (c *Client) setToken(token string) error {
-	c.Authentication.token = token
+	s := c.Authentication
+	s.mu.Lock()
+	s.token, s.source = token, nil
+	if token != "" {
+		s.source = StaticTokenSource(token)
+	}
+	s.mu.Unlock()
 	return nil
 }
 
@@ -135,9 +177,11 @@ func NewClient(options ...Option) (*Client, error) {
 		baseURL: url,
 		version: DefaultVersion,
 	}
-	c.Authentication = &AuthenticationService{}
+	c.Authentication = &AuthenticationService{client: c}
 	c.Addresses = &AddressesService{client: c}
 	c.Accounts = &AccountsService{client: c}
+	c.Partners = &PartnersService{client: c}
+	c.ProcessingOrders = &ProcessingOrdersService{client: c}
 	c.Transactions = &TransactionsService{client: c}
 	c.UserInfo = &UserInfoService{client: c}
 
@@ -159,42 +203,151 @@ func NewClient(options ...Option) (*Client, error) {
 //
 // For more information read https://github.com/google/go-github/issues/234
 func (c *Client) Call(m, u string, b interface{}, r interface{}) (*Response, error) {
-	req, err := c.NewRequest(m, u, b)
+	return c.CallContext(context.Background(), m, u, b, r)
+}
+
+// CallContext is like Call but carries the context ctx through the request.
+// The request is aborted as soon as ctx is canceled or its deadline exceeds.
+func (c *Client) CallContext(ctx context.Context, m, u string, b interface{}, r interface{}) (*Response, error) {
+	req, err := c.NewRequestContext(ctx, m, u, b)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.Do(req, r)
-	if err != nil {
-		return resp, err
+	return c.DoContext(ctx, req, r)
+}
+
+// An ErrorResponse reports an error caused by an API request. It can be
+// compared against the sentinel errors ErrUnauthorized, ErrForbidden,
+// ErrNotFound, ErrRateLimited and ErrServerError by using errors.Is.
+type ErrorResponse struct {
+	// Response is the HTTP response that caused this error.
+	Response *http.Response `json:"-"`
+
+	// Code is the error code returned by the API gateway, if any.
+	Code string `json:"code,omitempty"`
+	// Message is the error message returned by the API gateway, if any.
+	Message string `json:"message,omitempty"`
+	// Details contains further information about the error, if any.
+	Details string `json:"details,omitempty"`
+}
+
+// Error implements the error interface.
+func (r *ErrorResponse) Error() string {
+	msg := fmt.Sprintf("API call to %s failed: %s", r.Response.Request.URL.String(), r.Response.Status)
+	if r.Message != "" {
+		msg += ": " + r.Message
+	}
+	if r.Details != "" {
+		msg += " (" + r.Details + ")"
+	}
+	return msg
+}
+
+// Is reports whether the ErrorResponse matches the sentinel error target based
+// on the HTTP status code of the response.
+func (r *ErrorResponse) Is(target error) bool {
+	switch c := r.Response.StatusCode; target {
+	case ErrUnauthorized:
+		return c == http.StatusUnauthorized
+	case ErrForbidden:
+		return c == http.StatusForbidden
+	case ErrNotFound:
+		return c == http.StatusNotFound
+	case ErrRateLimited:
+		return c == http.StatusTooManyRequests
+	case ErrServerError:
+		return 500 <= c && c <= 599
 	}
+	return false
+}
 
-	return resp, err
+// UnmarshalJSON implements the json.Unmarshaler interface. The API gateway
+// returns the error code either as a JSON number or as a JSON string.
+func (r *ErrorResponse) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	r.Code = rawString(raw.Code)
+	r.Message = raw.Message
+	r.Details = rawString(raw.Details)
+	return nil
+}
+
+// rawString returns the content of a raw JSON value. Strings are unquoted,
+// every other value (e.g. numbers or objects) is returned as is.
+func rawString(b json.RawMessage) string {
+	if len(b) == 0 || string(b) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return s
+	}
+	return string(b)
 }
 
 // CheckResponse checks the API response for errors, and returns them if present.
 // A response is considered an error if it has a status code outside the 200 range.
+// API error responses are returned as *ErrorResponse. The error details returned
+// by the API gateway are decoded into it, if present.
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
 	}
-	return fmt.Errorf("API call to %s failed: %s", r.Request.URL.String(), r.Status)
+	errorResponse := &ErrorResponse{Response: r}
+	data, err := ioutil.ReadAll(r.Body)
+	if err == nil && len(data) > 0 {
+		// The body isn't necessarily JSON (e.g. if the error comes from a
+		// proxy), so decoding errors are ignored.
+		json.Unmarshal(data, errorResponse)
+	}
+	return errorResponse
 }
 
 // Do sends an API request and returns the API response.
 // The API response is JSON decoded and stored in the value pointed to by r, or
 // returned as an error if an API error has occurred. If r implements the
 // io.Writer interface, the raw response body will be written to r, without
-// attempting to first decode it.
+// attempting to first decode it. The request is sent with the context of req
+// and passes all middlewares of the client (see Use).
 func (c *Client) Do(req *http.Request, r interface{}) (*Response, error) {
-	resp, err := c.client.Do(req)
+	return c.DoContext(req.Context(), req, r)
+}
+
+// DoContext is like Do but sends the request with the context ctx. If ctx is
+// canceled or its deadline exceeds, the error returned is ctx.Err() rather than
+// the error of the underlying http client.
+func (c *Client) DoContext(ctx context.Context, req *http.Request, r interface{}) (*Response, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	// The attempt log is filled by the retry middleware.
+	attempts := new([]Attempt)
+	req = req.WithContext(context.WithValue(ctx, attemptLogKey{}, attempts))
+
+	resp, err := c.transport().RoundTrip(req)
 	if err != nil {
-		return nil, err
+		// Return the attempts which have been made, so they aren't lost.
+		var response *Response
+		if len(*attempts) > 0 {
+			response = &Response{Attempts: *attempts}
+		}
+		// If the context has been canceled, its error is probably more useful.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return response, ctxErr
+		}
+		return response, err
 	}
 	defer resp.Body.Close()
 
 	// Wrap response
-	response := &Response{Response: resp}
+	response := &Response{Response: resp, Attempts: *attempts}
 
 	err = CheckResponse(resp)
 	if err != nil {
@@ -204,13 +357,17 @@ func (c *Client) Do(req *http.Request, r interface{}) (*Response, error) {
 
 	if r != nil {
 		if w, ok := r.(io.Writer); ok {
-			io.Copy(w, resp.Body)
+			_, err = io.Copy(w, resp.Body)
 		} else {
 			err = json.NewDecoder(resp.Body).Decode(&r)
-			if err != nil {
-				// Return response in case the caller wants to inspect it further.
-				return response, err
+		}
+		if err != nil {
+			// Reading the body fails if the context is canceled midway.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return response, ctxErr
 			}
+			// Return response in case the caller wants to inspect it further.
+			return response, err
 		}
 	}
 	return response, err
@@ -222,6 +379,16 @@ func (c *Client) Do(req *http.Request, r interface{}) (*Response, error) {
 // specified without a preceding slash. If specified, the value pointed to by
 // body is JSON encoded and included as the request body.
 func (c *Client) NewRequest(m, urlStr string, body interface{}) (*http.Request, error) {
+	return c.NewRequestContext(context.Background(), m, urlStr, body)
+}
+
+// NewRequestContext is like NewRequest but the returned request carries the
+// context ctx.
+func (c *Client) NewRequestContext(ctx context.Context, m, urlStr string, body interface{}) (*http.Request, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
 	u, err := c.buildURLForRequest(urlStr)
 	if err != nil {
 		return nil, err
@@ -240,16 +407,11 @@ func (c *Client) NewRequest(m, urlStr string, body interface{}) (*http.Request,
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
-	// Apply Authentication if credentials are present.
-	// Documentation: https://developer.db.com/#/apidocumentation/apiauthorizationguide
-	if c.Authentication.HasAuth() {
-		req.Header.Add("Authorization", "Bearer "+c.Authentication.Token())
-	}
-
-	// Add some important headers.
+	// The Authorization and User-Agent headers are added by the middlewares of
+	// the client when the request is sent.
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("User-Agent", "dbapi/"+version)
 
 	return req, nil
 }
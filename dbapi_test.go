@@ -2,6 +2,8 @@ package dbapi
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -55,6 +57,8 @@ func TestNewClient(t *testing.T) {
 	// Are endpoints/resources present?
 	equals(t, &AddressesService{client: api}, api.Addresses)
 	equals(t, &AccountsService{client: api}, api.Accounts)
+	equals(t, &PartnersService{client: api}, api.Partners)
+	equals(t, &ProcessingOrdersService{client: api}, api.ProcessingOrders)
 	equals(t, &TransactionsService{client: api}, api.Transactions)
 	equals(t, &UserInfoService{client: api}, api.UserInfo)
 }
@@ -261,6 +265,57 @@ func TestDo_HTTPError(t *testing.T) {
 	assert(t, err != nil, "Expected error to be returned (expected HTTP 400 error).")
 }
 
+func TestDo_ErrorResponse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"code":403,"message":"Insufficient scope","details":"read_accounts"}`)
+	})
+
+	req, _ := testClient.NewRequest(http.MethodGet, "/", nil)
+	resp, err := testClient.Do(req, nil)
+
+	var errResp *ErrorResponse
+	assert(t, errors.As(err, &errResp), "Expected *ErrorResponse, got %#v.", err)
+	equals(t, resp.Response, errResp.Response)
+	equals(t, "403", errResp.Code)
+	equals(t, "Insufficient scope", errResp.Message)
+	equals(t, "read_accounts", errResp.Details)
+	assert(t, errors.Is(err, ErrForbidden), "Expected error to match ErrForbidden.")
+	assert(t, !errors.Is(err, ErrUnauthorized), "Expected error not to match ErrUnauthorized.")
+}
+
+func TestCheckResponse(t *testing.T) {
+	mockData := []struct {
+		StatusCode    int
+		Body          string
+		ExpectedCode  string
+		ExpectedError error
+	}{
+		{http.StatusUnauthorized, `{"code":"invalid_token","message":"Token expired"}`, "invalid_token", ErrUnauthorized},
+		{http.StatusForbidden, ``, "", ErrForbidden},
+		{http.StatusNotFound, `Not Found`, "", ErrNotFound},
+		{http.StatusTooManyRequests, `{"code":429}`, "429", ErrRateLimited},
+		{http.StatusBadGateway, `<html></html>`, "", ErrServerError},
+	}
+
+	for _, mock := range mockData {
+		req, _ := http.NewRequest(http.MethodGet, testAPI, nil)
+		resp := &http.Response{
+			Request:    req,
+			StatusCode: mock.StatusCode,
+			Status:     http.StatusText(mock.StatusCode),
+			Body:       ioutil.NopCloser(bytes.NewBufferString(mock.Body)),
+		}
+
+		err := CheckResponse(resp)
+		assert(t, errors.Is(err, mock.ExpectedError), "Expected %v to match %v.", err, mock.ExpectedError)
+		equals(t, mock.ExpectedCode, err.(*ErrorResponse).Code)
+	}
+}
+
 // Test handling of an error caused by the internal http client's Do() function.
 // A redirect loop is pretty unlikely to occur within the Cacheterrit API, but does allow us to exercise the right code path.
 func TestDo_RedirectLoop(t *testing.T) {
@@ -281,6 +336,59 @@ func TestDo_RedirectLoop(t *testing.T) {
 	}
 }
 
+func TestNewRequestContext(t *testing.T) {
+	api, err := NewClient(
+		SetToken(testAccessToken),
+		SetURL(testAPI),
+	)
+	ok(t, err)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	req, err := api.NewRequestContext(ctx, http.MethodGet, "/", nil)
+	ok(t, err)
+	equals(t, "value", req.Context().Value(key{}))
+
+	_, err = api.NewRequestContext(nil, http.MethodGet, "/", nil)
+	equals(t, ErrNilContext, err)
+}
+
+func TestDoContext_Canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"A":"a"}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := testClient.NewRequest(http.MethodGet, "/", nil)
+	_, err := testClient.DoContext(ctx, req, nil)
+	equals(t, context.Canceled, err)
+}
+
+func TestDoContext_DeadlineExceeded(t *testing.T) {
+	setup()
+	defer teardown()
+
+	done := make(chan struct{})
+	defer close(done)
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := testClient.CallContext(ctx, http.MethodGet, "/", nil, nil)
+	equals(t, context.DeadlineExceeded, err)
+}
+
 // setup sets up a test HTTP server along with a dbapi.Client that is configured
 // to talk to that test server. Tests should register handlers on mux which
 // provide mock responses for the API method being tested.
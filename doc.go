@@ -7,8 +7,9 @@ to let them build great, highly connected apps.
 In order to use the Deutsche Bank API you need to create an account at the
 developer portal (https://developer.db.com) and follow the instructions there.
 Short version: Create a new application, a new test user and authorize your
-application to get an access token. Authentication isn't handled by this
-package since there are great packages out there.
+application to get an access token. The access token can also be obtained by
+using the OAuth2 authorization code flow with PKCE which is implemented by the
+AuthenticationService.
 If you have a valid access token you can start to use this package.
 
     // Create a new client.
@@ -28,6 +29,18 @@ If you have a valid access token you can start to use this package.
     }
     fmt.Printf("%v", accounts)
 
+Every method which issues a request has a counterpart with a Context suffix that
+accepts a context.Context. It can be used to cancel in-flight requests or to put
+deadlines on them:
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    accounts, response, err := api.Accounts.GetAllContext(ctx)
+
+Budgeting features like categorization, spending reports and cash-flow
+forecasts, which are built on top of the accounts and transactions, are
+provided by the analytics subpackage (github.com/lukasmalkmus/dbapi/analytics).
+
 It is also possible to use a custom http client instead of http.DefaultClient
 (which is highly recommended!):
 
@@ -0,0 +1,107 @@
+package dbapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// A Fingerprint identifies a transaction, because the API doesn't return
+// transaction IDs. It is the hex encoded SHA-256 hash of the origin IBAN,
+// booking date, amount, counterparty, usage and the ordinal of the transaction
+// among identical transactions (see Transactions.Fingerprints).
+type Fingerprint string
+
+// Fingerprint returns the fingerprint of the transaction, assuming no
+// identical transaction precedes it. Use Transactions.Fingerprints to
+// fingerprint transactions which may contain identical ones, like two coffees
+// bought on the same day.
+func (t Transaction) Fingerprint() Fingerprint {
+	return t.fingerprint(0)
+}
+
+// fingerprint returns the fingerprint of the transaction with the given ordinal
+// among identical transactions.
+func (t Transaction) fingerprint(ordinal int) Fingerprint {
+	h := sha256.New()
+	for _, field := range []string{
+		string(t.OriginIBAN),
+		t.BookingDate.String(),
+		t.Amount.String(),
+		NormalizeSpace(t.CounterPartyName),
+		string(t.CounterPartyIBAN),
+		NormalizeSpace(t.Usage),
+		strconv.Itoa(ordinal),
+	} {
+		// Prefix every field with its length, so the encoding is unambiguous.
+		h.Write([]byte(strconv.Itoa(len(field)) + ":" + field))
+	}
+	return Fingerprint(hex.EncodeToString(h.Sum(nil)))
+}
+
+// Fingerprints returns the fingerprints of the transactions in their order.
+// Identical transactions are told apart by their ordinal: the first one gets
+// the same fingerprint as Transaction.Fingerprint returns, the second one a
+// different one and so on. Thus the fingerprints are stable across fetches as
+// long as no identical transaction vanishes.
+func (t Transactions) Fingerprints() []Fingerprint {
+	seen := make(map[Fingerprint]int, len(t))
+	fps := make([]Fingerprint, len(t))
+	for i, tx := range t {
+		base := tx.fingerprint(0)
+		fps[i] = tx.fingerprint(seen[base])
+		seen[base]++
+	}
+	return fps
+}
+
+// A TransactionMerge is the result of MergeTransactions.
+type TransactionMerge struct {
+	// New are the fetched transactions which haven't been stored before.
+	New Transactions
+	// Unchanged are the stored transactions which have been fetched again.
+	Unchanged Transactions
+	// Vanished are the stored transactions which haven't been fetched again.
+	Vanished Transactions
+}
+
+// MergeTransactions compares previously stored transactions with freshly
+// fetched ones by their fingerprints. New and Unchanged keep the order of the
+// fetched transactions, Vanished the order of the stored ones.
+//
+// If only the transactions of some accounts or of a period were fetched, the
+// stored transactions should be limited to them as well, otherwise all other
+// stored transactions are reported as vanished.
+func MergeTransactions(stored, fetched Transactions) TransactionMerge {
+	var m TransactionMerge
+
+	storedFps := stored.Fingerprints()
+	byFp := make(map[Fingerprint]int, len(stored))
+	for i, fp := range storedFps {
+		byFp[fp] = i
+	}
+
+	found := make(map[Fingerprint]bool, len(fetched))
+	for i, fp := range fetched.Fingerprints() {
+		j, ok := byFp[fp]
+		if !ok {
+			m.New = append(m.New, fetched[i])
+			continue
+		}
+		found[fp] = true
+		m.Unchanged = append(m.Unchanged, stored[j])
+	}
+	for i, fp := range storedFps {
+		if !found[fp] {
+			m.Vanished = append(m.Vanished, stored[i])
+		}
+	}
+	return m
+}
+
+// NormalizeSpace trims s and collapses all whitespace to single spaces. It is
+// used to compare names and usages, which often differ in their whitespace.
+func NormalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,61 @@
+package dbapi
+
+import "testing"
+
+func TestTransaction_Fingerprint(t *testing.T) {
+	tx := Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-2.5"), CounterPartyName: "Café", Usage: "POS MIT PIN. Kaffee", BookingDate: mustParseDate("2016-10-27")}
+
+	// The fingerprint is deterministic and tolerates reflowed text.
+	equals(t, tx.Fingerprint(), tx.Fingerprint())
+	reflowed := tx
+	reflowed.Usage = "POS MIT PIN.\n Kaffee "
+	equals(t, tx.Fingerprint(), reflowed.Fingerprint())
+	equals(t, 64, len(tx.Fingerprint()))
+
+	// Every field is part of the fingerprint.
+	for _, change := range []func(*Transaction){
+		func(tx *Transaction) { tx.OriginIBAN = "DE10000000000000000455" },
+		func(tx *Transaction) { tx.Amount = eur("-2.51") },
+		func(tx *Transaction) { tx.CounterPartyName = "Cafe" },
+		func(tx *Transaction) { tx.CounterPartyIBAN = "DE89370400440532013000" },
+		func(tx *Transaction) { tx.Usage = "Kaffee" },
+		func(tx *Transaction) { tx.BookingDate = mustParseDate("2016-10-28") },
+	} {
+		other := tx
+		change(&other)
+		assert(t, tx.Fingerprint() != other.Fingerprint(), "expected different fingerprint for %+v", other)
+	}
+}
+
+func TestTransactions_Fingerprints(t *testing.T) {
+	coffee := Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-2.5"), CounterPartyName: "Café", BookingDate: mustParseDate("2016-10-27")}
+	lunch := Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-8.9"), CounterPartyName: "Kantine", BookingDate: mustParseDate("2016-10-27")}
+
+	fps := Transactions{coffee, lunch, coffee}.Fingerprints()
+	equals(t, 3, len(fps))
+	equals(t, coffee.Fingerprint(), fps[0])
+	equals(t, lunch.Fingerprint(), fps[1])
+	assert(t, fps[0] != fps[2], "expected identical transactions to have different fingerprints")
+
+	// The fingerprints are stable across fetches.
+	equals(t, fps[2], Transactions{coffee, coffee}.Fingerprints()[1])
+}
+
+func TestMergeTransactions(t *testing.T) {
+	coffee := Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-2.5"), CounterPartyName: "Café", BookingDate: mustParseDate("2016-10-27")}
+	lunch := Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-8.9"), CounterPartyName: "Kantine", BookingDate: mustParseDate("2016-10-27")}
+	rent := Transaction{OriginIBAN: "DE10000000000000000454", Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", BookingDate: mustParseDate("2016-10-21")}
+
+	stored := Transactions{coffee, rent}
+	fetched := Transactions{coffee, lunch, coffee}
+
+	m := MergeTransactions(stored, fetched)
+	equals(t, Transactions{lunch, coffee}, m.New)
+	equals(t, Transactions{coffee}, m.Unchanged)
+	equals(t, Transactions{rent}, m.Vanished)
+
+	m = MergeTransactions(fetched, fetched)
+	equals(t, Transactions(nil), m.New)
+	equals(t, fetched, m.Unchanged)
+	equals(t, Transactions(nil), m.Vanished)
+}
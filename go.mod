@@ -0,0 +1,8 @@
+module github.com/lukasmalkmus/dbapi
+
+go 1.13
+
+require (
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -0,0 +1,273 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidIBAN is raised when an IBAN is malformed, has the wrong length for
+// its country or a wrong checksum.
+var ErrInvalidIBAN = errors.New("Invalid IBAN")
+
+// An IBAN is an International Bank Account Number (ISO 13616) in its electronic
+// format, without spaces and in upper case. Use ParseIBAN to obtain a valid one.
+type IBAN string
+
+// ibanFormat describes the IBAN format of a country.
+type ibanFormat struct {
+	// length is the total length of the IBAN.
+	length int
+	// bban is the format of the BBAN as a sequence of lengths and character
+	// classes: n (digits), a (upper case letters) and c (alphanumeric).
+	bban string
+	// bankCode is the length of the bank code at the start of the BBAN, if the
+	// remainder of the BBAN is the account number.
+	bankCode int
+}
+
+// ibanFormats lists the IBAN formats of the SEPA countries and some others as
+// published in the IBAN registry.
+var ibanFormats = map[string]ibanFormat{
+	"AD": {24, "4n4n12c", 0},
+	"AE": {23, "3n16n", 0},
+	"AT": {20, "5n11n", 5},
+	"BE": {16, "3n7n2n", 0},
+	"BG": {22, "4a4n2n8c", 0},
+	"CH": {21, "5n12c", 5},
+	"CY": {28, "3n5n16c", 0},
+	"CZ": {24, "4n6n10n", 0},
+	"DE": {22, "8n10n", 8},
+	"DK": {18, "4n9n1n", 0},
+	"EE": {20, "2n2n11n1n", 0},
+	"ES": {24, "4n4n1n1n10n", 0},
+	"FI": {18, "3n11n", 0},
+	"FR": {27, "5n5n11c2n", 0},
+	"GB": {22, "4a6n8n", 0},
+	"GI": {23, "4a15c", 0},
+	"GR": {27, "3n4n16c", 0},
+	"HR": {21, "7n10n", 7},
+	"HU": {28, "3n4n1n15n1n", 0},
+	"IE": {22, "4a6n8n", 0},
+	"IS": {26, "4n2n6n10n", 0},
+	"IT": {27, "1a5n5n12c", 0},
+	"LI": {21, "5n12c", 5},
+	"LT": {20, "5n11n", 5},
+	"LU": {20, "3n13c", 3},
+	"LV": {21, "4a13c", 4},
+	"MC": {27, "5n5n11c2n", 0},
+	"MT": {31, "4a5n18c", 0},
+	"NL": {18, "4a10n", 4},
+	"NO": {15, "4n6n1n", 0},
+	"PL": {28, "8n16n", 8},
+	"PT": {25, "4n4n11n2n", 0},
+	"RO": {24, "4a16c", 4},
+	"SA": {24, "2n18c", 2},
+	"SE": {24, "3n16n1n", 0},
+	"SI": {19, "5n8n2n", 0},
+	"SK": {24, "4n6n10n", 0},
+	"SM": {27, "1a5n5n12c", 0},
+	"TR": {26, "5n1n16c", 0},
+	"VA": {22, "3n15n", 0},
+}
+
+// ParseIBAN parses an IBAN in electronic or print format (e.g.
+// "de89 3704 0044 0532 0130 00"). Spaces are removed and letters are converted to
+// upper case. An error wrapping ErrInvalidIBAN is returned if the IBAN is
+// invalid.
+func ParseIBAN(s string) (IBAN, error) {
+	iban := IBAN(s).Normalize()
+	if err := iban.Validate(); err != nil {
+		return "", err
+	}
+	return iban, nil
+}
+
+// MustParseIBAN is like ParseIBAN but panics if the IBAN is invalid. It
+// simplifies the initialization of variables holding IBANs.
+func MustParseIBAN(s string) IBAN {
+	iban, err := ParseIBAN(s)
+	if err != nil {
+		panic(err)
+	}
+	return iban
+}
+
+// Normalize converts the IBAN to the electronic format by removing all
+// whitespace and converting it to upper case. It doesn't validate the IBAN.
+func (i IBAN) Normalize() IBAN {
+	return IBAN(strings.ToUpper(strings.Join(strings.Fields(string(i)), "")))
+}
+
+// Validate checks the structure, the country specific length and BBAN format and
+// the checksum (ISO 7064 mod 97-10) of the IBAN. The IBAN must be normalized
+// (see ParseIBAN). IBANs of countries which aren't known are only checked for
+// their general structure and the checksum.
+func (i IBAN) Validate() error {
+	if err := i.ValidateStructure(); err != nil {
+		return err
+	}
+	if mod97(string(i[4:]+i[:4])) != 1 {
+		return fmt.Errorf("%w %q: checksum mismatch", ErrInvalidIBAN, string(i))
+	}
+	return nil
+}
+
+// ValidateStructure is like Validate but doesn't check the checksum. It is used
+// for the IBANs passed to the API, since the IBANs of the accounts of the DB API
+// simulator have the right format but wrong checksums.
+func (i IBAN) ValidateStructure() error {
+	s := string(i)
+	if len(s) < 15 || len(s) > 34 {
+		return fmt.Errorf("%w %q: bad length", ErrInvalidIBAN, s)
+	}
+	if !matchClass(s[:2], 'a') || !matchClass(s[2:4], 'n') || !matchClass(s[4:], 'c') {
+		return fmt.Errorf("%w %q: malformed", ErrInvalidIBAN, s)
+	}
+	if f, ok := ibanFormats[s[:2]]; ok {
+		if len(s) != f.length {
+			return fmt.Errorf("%w %q: length must be %d for country %s", ErrInvalidIBAN, s, f.length, s[:2])
+		}
+		if !matchBBAN(s[4:], f.bban) {
+			return fmt.Errorf("%w %q: malformed BBAN for country %s", ErrInvalidIBAN, s, s[:2])
+		}
+	}
+	return nil
+}
+
+// parseRequestIBAN normalizes an IBAN which is passed to the API and checks its
+// structure (see ValidateStructure).
+func parseRequestIBAN(iban IBAN) (IBAN, error) {
+	iban = iban.Normalize()
+	if err := iban.ValidateStructure(); err != nil {
+		return "", err
+	}
+	return iban, nil
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 country code of the IBAN.
+func (i IBAN) CountryCode() string {
+	if len(i) < 2 {
+		return ""
+	}
+	return string(i[:2])
+}
+
+// CheckDigits returns the two check digits of the IBAN.
+func (i IBAN) CheckDigits() string {
+	if len(i) < 4 {
+		return ""
+	}
+	return string(i[2:4])
+}
+
+// BBAN returns the Basic Bank Account Number, the country specific part of the
+// IBAN.
+func (i IBAN) BBAN() string {
+	if len(i) < 4 {
+		return ""
+	}
+	return string(i[4:])
+}
+
+// BankCode returns the bank code contained in the IBAN, like the Bankleitzahl
+// of German IBANs. It is empty if the bank code of the country isn't known.
+func (i IBAN) BankCode() string {
+	f, ok := ibanFormats[i.CountryCode()]
+	if !ok || f.bankCode == 0 || len(i) != f.length {
+		return ""
+	}
+	return i.BBAN()[:f.bankCode]
+}
+
+// AccountNumber returns the account number contained in the IBAN, like the
+// Kontonummer of German IBANs. Leading zeros are removed. It is empty if the
+// account number of the country isn't known.
+func (i IBAN) AccountNumber() string {
+	f, ok := ibanFormats[i.CountryCode()]
+	if !ok || f.bankCode == 0 || len(i) != f.length {
+		return ""
+	}
+	if n := strings.TrimLeft(i.BBAN()[f.bankCode:], "0"); n != "" {
+		return n
+	}
+	return "0"
+}
+
+// String returns the IBAN in electronic format.
+func (i IBAN) String() string {
+	return string(i)
+}
+
+// Format returns the IBAN in print format, in groups of four characters
+// separated by spaces (e.g. "DE89 3704 0044 0532 0130 00").
+func (i IBAN) Format() string {
+	var b strings.Builder
+	for n, r := range string(i) {
+		if n > 0 && n%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The IBAN is
+// normalized but not validated, so an invalid IBAN returned by the API doesn't
+// prevent the whole response from being decoded.
+func (i *IBAN) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*i = IBAN(s).Normalize()
+	return nil
+}
+
+// mod97 computes the remainder of the division of the number, which is
+// represented by s with letters replaced by two digits (A = 10, ..., Z = 35), by
+// 97.
+func mod97(s string) int {
+	r := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			r = (r*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			r = (r*100 + int(c-'A'+10)) % 97
+		}
+	}
+	return r
+}
+
+// matchBBAN reports whether the BBAN matches the format (see ibanFormat).
+func matchBBAN(bban, format string) bool {
+	n := 0
+	for _, c := range format {
+		if c >= '0' && c <= '9' {
+			n = n*10 + int(c-'0')
+			continue
+		}
+		if n > len(bban) || !matchClass(bban[:n], c) {
+			return false
+		}
+		bban, n = bban[n:], 0
+	}
+	return bban == ""
+}
+
+// matchClass reports whether all characters of s belong to the character class:
+// n (digits), a (upper case letters) or c (digits and upper case letters).
+func matchClass(s string, class rune) bool {
+	for _, c := range s {
+		digit, letter := c >= '0' && c <= '9', c >= 'A' && c <= 'Z'
+		switch {
+		case class == 'n' && !digit,
+			class == 'a' && !letter,
+			class == 'c' && !digit && !letter:
+			return false
+		}
+	}
+	return true
+}
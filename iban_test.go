@@ -0,0 +1,113 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestParseIBAN(t *testing.T) {
+	mockData := []struct {
+		IBAN          string
+		ExpectedIBAN  IBAN
+		ExpectedError bool
+	}{
+		{"DE89370400440532013000", "DE89370400440532013000", false},
+		{"de89 3704 0044 0532 0130 00", "DE89370400440532013000", false},
+		{" GB82 WEST 1234 5698 7654 32 ", "GB82WEST12345698765432", false},
+		{"FR1420041010050500013M02606", "FR1420041010050500013M02606", false},
+		{"NO9386011117947", "NO9386011117947", false},
+		{"DE97000000000000000454", "DE97000000000000000454", false},
+		// Wrong checksum.
+		{"DE10000000000000000454", "", true},
+		{"DE88370400440532013000", "", true},
+		// Wrong length for the country.
+		{"DE8937040044053201300", "", true},
+		// Letters where the German BBAN has digits.
+		{"DE89370400440532O13000", "", true},
+		// Malformed.
+		{"", "", true},
+		{"1234567890123456", "", true},
+		{"DE89-3704-0044-0532-0130-00", "", true},
+	}
+
+	for _, mock := range mockData {
+		iban, err := ParseIBAN(mock.IBAN)
+		equals(t, mock.ExpectedIBAN, iban)
+		equals(t, mock.ExpectedError, err != nil)
+		if err != nil {
+			assert(t, errors.Is(err, ErrInvalidIBAN), "Expected ErrInvalidIBAN, got %v.", err)
+		}
+	}
+}
+
+func TestIBAN_ValidateStructure(t *testing.T) {
+	mockData := []struct {
+		IBAN          IBAN
+		ExpectedError bool
+	}{
+		{"DE89370400440532013000", false},
+		// The checksum isn't checked, like for the IBANs of the simulator.
+		{"DE10000000000000000454", false},
+		{"DE8937040044053201300", true},
+		{"DE89370400440532O13000", true},
+		{"", true},
+	}
+
+	for _, mock := range mockData {
+		err := mock.IBAN.ValidateStructure()
+		equals(t, mock.ExpectedError, err != nil)
+		if err != nil {
+			assert(t, errors.Is(err, ErrInvalidIBAN), "Expected ErrInvalidIBAN, got %v.", err)
+		}
+	}
+}
+
+func TestIBAN_Parts(t *testing.T) {
+	iban := MustParseIBAN("DE89 3704 0044 0532 0130 00")
+
+	equals(t, "DE", iban.CountryCode())
+	equals(t, "89", iban.CheckDigits())
+	equals(t, "370400440532013000", iban.BBAN())
+	equals(t, "37040044", iban.BankCode())
+	equals(t, "532013000", iban.AccountNumber())
+	equals(t, "DE89370400440532013000", iban.String())
+	equals(t, "DE89 3704 0044 0532 0130 00", iban.Format())
+
+	// The bank code of British IBANs isn't known.
+	gb := MustParseIBAN("GB82WEST12345698765432")
+	equals(t, "", gb.BankCode())
+	equals(t, "", gb.AccountNumber())
+}
+
+func TestIBAN_Normalize(t *testing.T) {
+	equals(t, IBAN("DE10000000000000000454"), IBAN(" de10 0000 0000 0000\t0004 54 ").Normalize())
+	// The IBAN isn't validated.
+	equals(t, IBAN("DE1"), IBAN("de 1").Normalize())
+}
+
+func TestIBAN_UnmarshalJSON(t *testing.T) {
+	var v struct {
+		IBAN IBAN `json:"iban"`
+	}
+
+	// Invalid IBANs returned by the API are only normalized.
+	ok(t, json.Unmarshal([]byte(`{"iban":"de10 0000 0000 0000 0004 54"}`), &v))
+	equals(t, IBAN("DE10000000000000000454"), v.IBAN)
+}
+
+func TestIBANFormats(t *testing.T) {
+	// The BBAN formats must add up to the IBAN lengths.
+	for country, f := range ibanFormats {
+		n, length := 0, 4
+		for _, c := range f.bban {
+			if c >= '0' && c <= '9' {
+				n = n*10 + int(c-'0')
+				continue
+			}
+			length, n = length+n, 0
+		}
+		equals(t, country+strconv.Itoa(f.length), country+strconv.Itoa(length))
+	}
+}
@@ -0,0 +1,129 @@
+package dbapi
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// A RoundTripper executes a single HTTP transaction. It has the same method set
+// as http.RoundTripper, so both can be used interchangeably.
+type RoundTripper interface {
+	RoundTrip(*http.Request) (*http.Response, error)
+}
+
+// The RoundTripperFunc type is an adapter to allow the use of ordinary functions
+// as RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// A Middleware wraps the RoundTripper next and returns a RoundTripper which
+// usually calls next. Middlewares can inspect or modify requests and responses,
+// short-circuit requests or call next multiple times. Like a RoundTripper, a
+// Middleware should not modify the request passed to it but a copy of it.
+type Middleware func(next RoundTripper) RoundTripper
+
+// Use specifies middlewares which wrap every request made through Client.Do.
+// Middlewares are called in the order in which they have been added, so the
+// first one sees the request first and the response last. The middlewares of
+// the client itself always run before any middleware added with Use:
+//
+//  1. retries (see SetRetryPolicy), so every attempt passes all others
+//  2. the User-Agent header
+//  3. the Authorization header (if authentication credentials are present)
+//  4. all middlewares added with Use
+func Use(middleware ...Middleware) Option {
+	return func(c *Client) error { return c.use(middleware...) }
+}
+func (c *Client) use(middleware ...Middleware) error {
+	for _, m := range middleware {
+		if m == nil {
+			return ErrInvalidMiddleware
+		}
+	}
+	c.middleware = append(c.middleware, middleware...)
+	return nil
+}
+
+// transport returns the RoundTripper which sends requests through the chain of
+// all middlewares to the http client.
+func (c *Client) transport() RoundTripper {
+	chain := make([]Middleware, 0, len(c.middleware)+3)
+	chain = append(chain,
+		retryMiddleware(c.retryPolicy),
+		userAgentMiddleware("dbapi/"+version),
+		authMiddleware(c.Authentication),
+	)
+	chain = append(chain, c.middleware...)
+
+	var rt RoundTripper = RoundTripperFunc(c.client.Do)
+	for i := len(chain) - 1; i >= 0; i-- {
+		rt = chain[i](rt)
+	}
+	return rt
+}
+
+// userAgentMiddleware returns a Middleware which sets the User-Agent header.
+func userAgentMiddleware(ua string) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("User-Agent", ua)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// authMiddleware returns a Middleware which authenticates requests with a bearer
+// token from the token source of the AuthenticationService, if present. If the
+// API rejects a refreshable token, the token is refreshed and the request is
+// retried once.
+// Documentation: https://developer.db.com/#/apidocumentation/apiauthorizationguide
+func authMiddleware(s *AuthenticationService) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			source := s.TokenSource()
+			if source == nil {
+				return next.RoundTrip(req)
+			}
+
+			tok, err := s.authorize(req.Context(), source)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := next.RoundTrip(withBearer(req, tok))
+			if err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+
+			// Retry once with a refreshed token, if possible.
+			r, refreshable := source.(*refreshTokenSource)
+			if !refreshable || !replayable(req) || !r.invalidate(tok) {
+				return resp, err
+			}
+			retryReq, rewindErr := rewindRequest(req)
+			if rewindErr != nil {
+				return resp, err
+			}
+			newTok, refreshErr := s.authorize(req.Context(), source)
+			if refreshErr != nil || newTok.AccessToken == tok.AccessToken {
+				return resp, err
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			return next.RoundTrip(withBearer(retryReq, newTok))
+		})
+	}
+}
+
+// withBearer returns a copy of the request with the Authorization header set to
+// the access token of tok.
+func withBearer(req *http.Request, tok *Token) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	return req
+}
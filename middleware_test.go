@@ -0,0 +1,116 @@
+package dbapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUse(t *testing.T) {
+	noop := func(next RoundTripper) RoundTripper { return next }
+
+	mockData := []struct {
+		Middleware         []Middleware
+		ExpectedMiddleware int
+		ExpectedError      error
+	}{
+		{nil, 0, nil},
+		{[]Middleware{noop, noop}, 2, nil},
+		{[]Middleware{noop, nil}, 0, ErrInvalidMiddleware},
+	}
+
+	for _, mock := range mockData {
+		api, err := NewClient(
+			Use(mock.Middleware...),
+		)
+		if api != nil {
+			equals(t, mock.ExpectedMiddleware, len(api.middleware))
+		}
+		equals(t, mock.ExpectedError, err)
+	}
+}
+
+func TestUse_Order(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, "a,b", r.Header.Get("X-Trace"))
+		fmt.Fprint(w, `{}`)
+	})
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				req = req.Clone(req.Context())
+				req.Header.Set("X-Trace", strings.TrimPrefix(req.Header.Get("X-Trace")+","+name, ","))
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, name+" response")
+				return resp, err
+			})
+		}
+	}
+	ok(t, testClient.Options(Use(trace("a"), trace("b"))))
+
+	_, err := testClient.Call(http.MethodGet, "/", nil, nil)
+	ok(t, err)
+	equals(t, []string{"a request", "b request", "b response", "a response"}, calls)
+}
+
+func TestUse_BuiltinHeaders(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	var header http.Header
+	ok(t, testClient.Options(Use(func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			header = req.Header
+			return next.RoundTrip(req)
+		})
+	})))
+
+	_, err := testClient.Call(http.MethodGet, "/", nil, nil)
+	ok(t, err)
+	equals(t, "Bearer "+testAccessToken, header.Get("Authorization"))
+	equals(t, "dbapi/"+version, header.Get("User-Agent"))
+	equals(t, "application/json", header.Get("Accept"))
+}
+
+func TestUse_FaultInjection(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	// Fail the first request before it reaches the server, retries have to pass
+	// the middleware again.
+	faults := 1
+	errFault := errors.New("injected fault")
+	ok(t, testClient.Options(
+		SetRetryPolicy(testRetryPolicy),
+		Use(func(next RoundTripper) RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if faults > 0 {
+					faults--
+					return nil, errFault
+				}
+				return next.RoundTrip(req)
+			})
+		}),
+	))
+
+	resp, err := testClient.Call(http.MethodGet, "/", nil, nil)
+	ok(t, err)
+	equals(t, 2, len(resp.Attempts))
+	equals(t, errFault, resp.Attempts[0].Err)
+}
@@ -0,0 +1,376 @@
+package dbapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidAmount is raised when an amount can't be parsed or can't be
+	// represented in the minor unit of its currency without rounding.
+	ErrInvalidAmount = errors.New("Invalid amount")
+	// ErrCurrencyMismatch is raised when amounts of different currencies are
+	// combined.
+	ErrCurrencyMismatch = errors.New("Currency mismatch")
+)
+
+// A Currency is an ISO 4217 currency code.
+type Currency string
+
+// Some common currencies.
+const (
+	EUR Currency = "EUR"
+	USD Currency = "USD"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	JPY Currency = "JPY"
+)
+
+// DefaultCurrency is the currency of amounts which are decoded from the API.
+// The API only returns accounts in the currency EUR.
+const DefaultCurrency = EUR
+
+// minorUnits lists the currencies which don't have two decimal places.
+var minorUnits = map[Currency]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0, "XAF": 0,
+	"XOF": 0,
+}
+
+// Digits returns the number of decimal places of the minor unit of the currency
+// (e.g. 2 for EUR, 0 for JPY).
+func (c Currency) Digits() int {
+	if d, ok := minorUnits[c]; ok {
+		return d
+	}
+	return 2
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// Money is an exact amount of money. It is stored as an integer number of minor
+// units (e.g. cents) of its currency, so calculations never lose precision.
+//
+// The zero value is zero money without currency. It adopts the currency of the
+// other operand in arithmetic and comparisons, so it can be used as initial
+// value of a sum. Combining amounts of two different currencies is a
+// programming error and panics with ErrCurrencyMismatch.
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// NewMoney returns the amount of minor units (e.g. cents) of the currency.
+func NewMoney(minor int64, currency Currency) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// ParseMoney parses a decimal number like "-35.56" or "1.5e3" as an amount of
+// the currency. An error ErrInvalidAmount is returned if the number can't be
+// parsed or has more decimal places than the minor unit of the currency.
+func ParseMoney(s string, currency Currency) (Money, error) {
+	minor, err := parseMinor(strings.TrimSpace(s), currency.Digits())
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// parseMinor parses the decimal number s into an integer number of units with
+// the given number of decimal places without going through a float.
+func parseMinor(s string, digits int) (int64, error) {
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	// Split off the exponent.
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > 100 || e < -100 {
+			return 0, ErrInvalidAmount
+		}
+		exp, s = e, s[:i]
+	}
+
+	// Split off the fraction.
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+	if intPart == "" && frac == "" {
+		return 0, ErrInvalidAmount
+	}
+	mantissa := intPart + frac
+	for _, r := range mantissa {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	// Shift the decimal point to the minor unit. Digits shifted out must be
+	// zero, otherwise the amount can't be represented without rounding.
+	shift := exp - len(frac) + digits
+	if shift >= 0 {
+		mantissa += strings.Repeat("0", shift)
+	} else {
+		cut := len(mantissa) + shift
+		if cut < 0 {
+			cut = 0
+		}
+		if strings.Trim(mantissa[cut:], "0") != "" {
+			return 0, ErrInvalidAmount
+		}
+		mantissa = mantissa[:cut]
+	}
+
+	mantissa = strings.TrimLeft(mantissa, "0")
+	if mantissa == "" {
+		return 0, nil
+	}
+	minor, err := strconv.ParseInt(mantissa, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if neg {
+		minor = -minor
+	}
+	return minor, nil
+}
+
+// Minor returns the amount in minor units (e.g. cents) of its currency.
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the currency of the amount.
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// Decimal returns the amount as decimal number with the number of decimal places
+// of its currency (e.g. "-35.56").
+func (m Money) Decimal() string {
+	digits := m.currency.Digits()
+	abs := m.minor
+	sign := ""
+	if abs < 0 {
+		sign = "-"
+	}
+	// Use uint64 so the minimum int64 value doesn't overflow.
+	u := uint64(abs)
+	if abs < 0 {
+		u = uint64(-(abs + 1)) + 1
+	}
+	s := strconv.FormatUint(u, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// String returns the amount and its currency (e.g. "-35.56 EUR").
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + string(m.currency)
+}
+
+// Float64 returns the amount as floating point number in major units. It is
+// meant for displaying and statistics only, never for calculations.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return f
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Sign returns -1, 0 or +1 depending on whether the amount is negative, zero or
+// positive.
+func (m Money) Sign() int {
+	switch {
+	case m.minor < 0:
+		return -1
+	case m.minor > 0:
+		return 1
+	}
+	return 0
+}
+
+// Add returns the sum m+o.
+func (m Money) Add(o Money) Money {
+	c := m.common(o)
+	return Money{minor: m.minor + o.minor, currency: c}
+}
+
+// Sub returns the difference m-o.
+func (m Money) Sub(o Money) Money {
+	c := m.common(o)
+	return Money{minor: m.minor - o.minor, currency: c}
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Abs returns the absolute amount of m.
+func (m Money) Abs() Money {
+	if m.minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Mul returns the amount multiplied by n.
+func (m Money) Mul(n int64) Money {
+	return Money{minor: m.minor * n, currency: m.currency}
+}
+
+// Cmp compares m and o and returns -1 if m < o, 0 if m == o and +1 if m > o.
+func (m Money) Cmp(o Money) int {
+	m.common(o)
+	switch {
+	case m.minor < o.minor:
+		return -1
+	case m.minor > o.minor:
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether m and o are the same amount of the same currency. An
+// amount without currency (like the zero value) equals the same amount of every
+// currency.
+func (m Money) Equal(o Money) bool {
+	if m.minor != o.minor {
+		return false
+	}
+	return m.currency == o.currency || m.currency == "" || o.currency == ""
+}
+
+// Allocate splits the amount into parts which are proportional to the ratios
+// without losing a single minor unit: the remainder is distributed one minor
+// unit at a time, starting with the first part. It panics if a ratio is negative
+// or all ratios are zero.
+func (m Money) Allocate(ratios ...int) []Money {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			panic("dbapi: negative ratio")
+		}
+		total += int64(r)
+	}
+	if total == 0 {
+		panic("dbapi: no ratio greater than zero")
+	}
+
+	abs, sign := m.minor, int64(1)
+	if abs < 0 {
+		abs, sign = -abs, -1
+	}
+	parts := make([]Money, len(ratios))
+	remainder := abs
+	for i, r := range ratios {
+		share := abs / total * int64(r)
+		share += abs % total * int64(r) / total
+		parts[i] = Money{minor: share, currency: m.currency}
+		remainder -= share
+	}
+	for i := 0; remainder > 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].minor++
+		remainder--
+	}
+	for i := range parts {
+		parts[i].minor *= sign
+	}
+	return parts
+}
+
+// Split splits the amount into n equal parts without losing a single minor unit.
+// The remainder is distributed one minor unit at a time, starting with the first
+// part. It panics if n is less than 1.
+func (m Money) Split(n int) []Money {
+	if n < 1 {
+		panic("dbapi: split into less than one part")
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The amount is encoded as
+// JSON number.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a JSON
+// number or a string containing a number. The number is parsed exactly, without
+// going through a float. The currency is set to DefaultCurrency unless the
+// amount already has a currency.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if string(b) == "null" {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+
+	currency := m.currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	money, err := ParseMoney(s, currency)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, s)
+	}
+	*m = money
+	return nil
+}
+
+// common returns the common currency of m and o. It panics if the currencies
+// differ.
+func (m Money) common(o Money) Currency {
+	switch {
+	case m.currency == o.currency || o.currency == "":
+		return m.currency
+	case m.currency == "":
+		return o.currency
+	}
+	panic(fmt.Errorf("dbapi: %w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency))
+}
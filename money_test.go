@@ -0,0 +1,141 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	mockData := []struct {
+		Amount        string
+		Currency      Currency
+		ExpectedMinor int64
+		ExpectedError error
+	}{
+		{"31236.95", EUR, 3123695, nil},
+		{"-35.56", EUR, -3556, nil},
+		{"+250", EUR, 25000, nil},
+		{"0.1", EUR, 10, nil},
+		{".5", EUR, 50, nil},
+		{"1.500", EUR, 150, nil},
+		{"1.5e3", EUR, 150000, nil},
+		{"1234E-2", EUR, 1234, nil},
+		{"1000", JPY, 1000, nil},
+		{"1.234", "KWD", 1234, nil},
+		{"0", EUR, 0, nil},
+		{"0.125", EUR, 0, ErrInvalidAmount},
+		{"1.5", JPY, 0, ErrInvalidAmount},
+		{"", EUR, 0, ErrInvalidAmount},
+		{".", EUR, 0, ErrInvalidAmount},
+		{"12,50", EUR, 0, ErrInvalidAmount},
+		{"1e", EUR, 0, ErrInvalidAmount},
+		{"99999999999999999999", EUR, 0, ErrInvalidAmount},
+	}
+
+	for _, mock := range mockData {
+		m, err := ParseMoney(mock.Amount, mock.Currency)
+		equals(t, mock.ExpectedError, err)
+		if err == nil {
+			equals(t, NewMoney(mock.ExpectedMinor, mock.Currency), m)
+		}
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	mockData := []struct {
+		Money           Money
+		ExpectedDecimal string
+		ExpectedString  string
+	}{
+		{NewMoney(3123695, EUR), "31236.95", "31236.95 EUR"},
+		{NewMoney(-5, EUR), "-0.05", "-0.05 EUR"},
+		{NewMoney(0, EUR), "0.00", "0.00 EUR"},
+		{NewMoney(1000, JPY), "1000", "1000 JPY"},
+		{Money{}, "0.00", "0.00"},
+	}
+
+	for _, mock := range mockData {
+		equals(t, mock.ExpectedDecimal, mock.Money.Decimal())
+		equals(t, mock.ExpectedString, mock.Money.String())
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	// Summing the amounts as float64 gives 0.30000000000000004.
+	var sum Money
+	for i := 0; i < 3; i++ {
+		sum = sum.Add(eur("0.1"))
+	}
+	equals(t, eur("0.3"), sum)
+
+	equals(t, eur("-0.2"), eur("0.1").Sub(eur("0.3")))
+	equals(t, eur("0.2"), eur("-0.2").Abs())
+	equals(t, eur("-0.2"), eur("0.2").Neg())
+	equals(t, eur("1.5"), eur("0.5").Mul(3))
+	equals(t, -1, eur("1").Cmp(eur("2")))
+	equals(t, 0, eur("2").Cmp(eur("2")))
+	equals(t, 1, eur("3").Cmp(Money{}))
+	equals(t, -1, eur("-3").Sign())
+	assert(t, eur("0").Equal(Money{}), "Expected zero amounts to be equal.")
+	assert(t, !eur("1").Equal(NewMoney(100, USD)), "Expected amounts of different currencies to differ.")
+
+	defer func() {
+		err, _ := recover().(error)
+		assert(t, errors.Is(err, ErrCurrencyMismatch), "Expected panic with ErrCurrencyMismatch, got %v.", err)
+	}()
+	eur("1").Add(NewMoney(100, USD))
+}
+
+func TestMoney_Allocate(t *testing.T) {
+	mockData := []struct {
+		Money         Money
+		Ratios        []int
+		ExpectedParts []Money
+	}{
+		{eur("100"), []int{1, 1, 1}, []Money{eur("33.34"), eur("33.33"), eur("33.33")}},
+		{eur("0.05"), []int{3, 7}, []Money{eur("0.02"), eur("0.03")}},
+		{eur("-10"), []int{1, 2}, []Money{eur("-3.34"), eur("-6.66")}},
+		{eur("0.03"), []int{0, 1, 1}, []Money{eur("0"), eur("0.02"), eur("0.01")}},
+	}
+
+	for _, mock := range mockData {
+		parts := mock.Money.Allocate(mock.Ratios...)
+		equals(t, mock.ExpectedParts, parts)
+
+		var sum Money
+		for _, p := range parts {
+			sum = sum.Add(p)
+		}
+		equals(t, mock.Money, sum)
+	}
+
+	equals(t, []Money{eur("0.34"), eur("0.33"), eur("0.33")}, eur("1").Split(3))
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var v struct {
+		Amount Money `json:"amount"`
+	}
+	ok(t, json.Unmarshal([]byte(`{"amount":-96.16}`), &v))
+	equals(t, eur("-96.16"), v.Amount)
+	ok(t, json.Unmarshal([]byte(`{"amount":"31236.95"}`), &v))
+	equals(t, eur("31236.95"), v.Amount)
+
+	err := json.Unmarshal([]byte(`{"amount":1.005}`), &v)
+	assert(t, errors.Is(err, ErrInvalidAmount), "Expected ErrInvalidAmount, got %v.", err)
+
+	b, err := json.Marshal(v)
+	ok(t, err)
+	equals(t, `{"amount":31236.95}`, string(b))
+}
+
+// eur parses the amount as Money in the currency EUR. It panics if the amount
+// is invalid.
+func eur(amount string) Money {
+	m, err := ParseMoney(amount, EUR)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
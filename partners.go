@@ -0,0 +1,166 @@
+package dbapi
+
+import (
+	"context"
+	"net/http"
+)
+
+// The PartnersService binds to the HTTP endpoints which belong to the partners
+// resource. It replaces the UserInfoService.
+type PartnersService struct {
+	client *Client
+}
+
+// PartnerType distinguishes natural from legal persons.
+type PartnerType string
+
+// Available partner types.
+const (
+	NaturalPersonPartner PartnerType = "NATURAL_PERSON"
+	LegalPersonPartner   PartnerType = "LEGAL_PERSON"
+)
+
+// Gender is the gender of a natural person. Genders which aren't known to this
+// package are kept as returned by the API.
+type Gender string
+
+// Available genders.
+const (
+	Female  Gender = "FEMALE"
+	Male    Gender = "MALE"
+	Diverse Gender = "DIVERSE"
+)
+
+func (g Gender) String() string {
+	return string(g)
+}
+
+// Known reports whether the gender is one of the genders defined by this
+// package.
+func (g Gender) Known() bool {
+	switch g {
+	case Female, Male, Diverse:
+		return true
+	}
+	return false
+}
+
+// MaritalStatus is the marital status of a natural person.
+type MaritalStatus string
+
+// Available marital statuses.
+const (
+	MaritalStatusSingle                MaritalStatus = "SINGLE"
+	MaritalStatusMarried               MaritalStatus = "MARRIED"
+	MaritalStatusDivorced              MaritalStatus = "DIVORCED"
+	MaritalStatusWidowed               MaritalStatus = "WIDOWED"
+	MaritalStatusRegisteredPartnership MaritalStatus = "REGISTERED_PARTNERSHIP"
+	MaritalStatusSeparated             MaritalStatus = "SEPARATED"
+)
+
+// ContactType tells if an email address or phone number is used privately or
+// for business.
+type ContactType string
+
+// Available contact types.
+const (
+	PrivateContact  ContactType = "PRIVATE"
+	BusinessContact ContactType = "BUSINESS"
+)
+
+// PhoneType is the type of a phone number.
+type PhoneType string
+
+// Available phone types.
+const (
+	MobilePhone   PhoneType = "MOBILE"
+	LandlinePhone PhoneType = "LANDLINE"
+	FaxPhone      PhoneType = "FAX"
+)
+
+// A Partner is a customer of the bank. It is either a natural or a legal
+// person, depending on its PartnerType.
+type Partner struct {
+	PartnerType    PartnerType    `json:"partnerType,omitempty"`
+	NaturalPerson  *NaturalPerson `json:"naturalPerson,omitempty"`
+	LegalPerson    *LegalPerson   `json:"legalPerson,omitempty"`
+	EmailAddresses []EmailAddress `json:"emailAddresses,omitempty"`
+	PhoneNumbers   []PhoneNumber  `json:"phoneNumbers,omitempty"`
+	TaxResidencies []TaxResidency `json:"taxResidencies,omitempty"`
+}
+
+// A NaturalPerson holds the personal information of a partner who is a natural
+// person. Nationality is an ISO 3166-1 alpha-2 country code.
+type NaturalPerson struct {
+	FirstName     string        `json:"firstName,omitempty"`
+	LastName      string        `json:"lastName,omitempty"`
+	AcademicTitle string        `json:"academicTitle,omitempty"`
+	DateOfBirth   Date          `json:"dateOfBirth,omitempty"`
+	PlaceOfBirth  string        `json:"placeOfBirth,omitempty"`
+	Gender        Gender        `json:"gender,omitempty"`
+	Nationality   string        `json:"nationality,omitempty"`
+	MaritalStatus MaritalStatus `json:"maritalStatus,omitempty"`
+}
+
+// A LegalPerson holds the information of a partner who is a legal person (e.g.
+// a company). CountryOfIncorporation is an ISO 3166-1 alpha-2 country code.
+type LegalPerson struct {
+	Name                   string `json:"name,omitempty"`
+	LegalForm              string `json:"legalForm,omitempty"`
+	RegistrationNumber     string `json:"registrationNumber,omitempty"`
+	DateOfIncorporation    Date   `json:"dateOfIncorporation,omitempty"`
+	CountryOfIncorporation string `json:"countryOfIncorporation,omitempty"`
+}
+
+// An EmailAddress is an email address of a partner.
+type EmailAddress struct {
+	EmailAddress string      `json:"emailAddress,omitempty"`
+	Type         ContactType `json:"emailType,omitempty"`
+}
+
+// A PhoneNumber is a phone number of a partner.
+type PhoneNumber struct {
+	Number      string      `json:"number,omitempty"`
+	Type        PhoneType   `json:"phoneType,omitempty"`
+	ContactType ContactType `json:"contactType,omitempty"`
+}
+
+// A TaxResidency is a country in which a partner is liable to pay taxes.
+// Country is an ISO 3166-1 alpha-2 country code.
+type TaxResidency struct {
+	Country string `json:"country,omitempty"`
+	TaxID   string `json:"taxId,omitempty"`
+}
+
+// Get retrieves the partner data (e.g. name, contact information and tax
+// residencies) of the current user.
+func (s *PartnersService) Get() (*Partner, *Response, error) {
+	return s.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses the context ctx for the request.
+func (s *PartnersService) GetContext(ctx context.Context) (*Partner, *Response, error) {
+	u := "/partners"
+	r := new(Partner)
+
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
+	return r, resp, err
+}
+
+// UserInfo maps the partner onto the UserInfo type of the deprecated /userInfo
+// endpoint. Legal persons have no personal information, so only the name is
+// mapped to LastName.
+func (p *Partner) UserInfo() *UserInfo {
+	switch {
+	case p.NaturalPerson != nil:
+		return &UserInfo{
+			FirstName:   p.NaturalPerson.FirstName,
+			LastName:    p.NaturalPerson.LastName,
+			DateOfBirth: p.NaturalPerson.DateOfBirth,
+			Gender:      p.NaturalPerson.Gender,
+		}
+	case p.LegalPerson != nil:
+		return &UserInfo{LastName: p.LegalPerson.Name}
+	}
+	return &UserInfo{}
+}
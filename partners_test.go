@@ -0,0 +1,80 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPartnersService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	exp := &Partner{
+		PartnerType: NaturalPersonPartner,
+		NaturalPerson: &NaturalPerson{
+			FirstName:     "Claudia",
+			LastName:      "Klar",
+			DateOfBirth:   mustParseDate("1977-03-02"),
+			PlaceOfBirth:  "Frankfurt",
+			Gender:        "FEMALE",
+			Nationality:   "DE",
+			MaritalStatus: MaritalStatusMarried,
+		},
+		EmailAddresses: []EmailAddress{{EmailAddress: "claudia.klar@example.com", Type: PrivateContact}},
+		PhoneNumbers:   []PhoneNumber{{Number: "+49 69 910 00", Type: MobilePhone, ContactType: PrivateContact}},
+		TaxResidencies: []TaxResidency{{Country: "DE", TaxID: "12345678901"}},
+	}
+
+	testMux.HandleFunc("/v1/partners", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"partnerType":"NATURAL_PERSON","naturalPerson":{"firstName":"Claudia","lastName":"Klar","dateOfBirth":"1977-03-02","placeOfBirth":"Frankfurt","gender":"FEMALE","nationality":"DE","maritalStatus":"MARRIED"},"emailAddresses":[{"emailAddress":"claudia.klar@example.com","emailType":"PRIVATE"}],"phoneNumbers":[{"number":"+49 69 910 00","phoneType":"MOBILE","contactType":"PRIVATE"}],"taxResidencies":[{"country":"DE","taxId":"12345678901"}]}`)
+	})
+
+	act, _, err := testClient.Partners.Get()
+	ok(t, err)
+	equals(t, exp, act)
+}
+
+func TestPartner_UserInfo(t *testing.T) {
+	mockData := []struct {
+		Partner          *Partner
+		ExpectedUserInfo *UserInfo
+	}{
+		{
+			&Partner{PartnerType: NaturalPersonPartner, NaturalPerson: &NaturalPerson{FirstName: "Claudia", LastName: "Klar", DateOfBirth: mustParseDate("1977-03-02"), Gender: "FEMALE"}},
+			&UserInfo{FirstName: "Claudia", LastName: "Klar", DateOfBirth: mustParseDate("1977-03-02"), Gender: "FEMALE"},
+		},
+		{
+			&Partner{PartnerType: LegalPersonPartner, LegalPerson: &LegalPerson{Name: "Klar GmbH"}},
+			&UserInfo{LastName: "Klar GmbH"},
+		},
+		{&Partner{}, &UserInfo{}},
+	}
+
+	for _, mock := range mockData {
+		equals(t, mock.ExpectedUserInfo, mock.Partner.UserInfo())
+	}
+}
+
+func TestGender(t *testing.T) {
+	mockData := []struct {
+		data  string
+		exp   Gender
+		known bool
+	}{
+		{`"FEMALE"`, Female, true},
+		{`"MALE"`, Male, true},
+		{`"DIVERSE"`, Diverse, true},
+		{`"UNSPECIFIED"`, "UNSPECIFIED", false},
+	}
+
+	for _, tt := range mockData {
+		var act Gender
+		ok(t, json.Unmarshal([]byte(tt.data), &act))
+		equals(t, tt.exp, act)
+		equals(t, tt.known, act.Known())
+		equals(t, string(tt.exp), act.String())
+	}
+}
@@ -0,0 +1,180 @@
+package dbapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidPostalCode is raised when a postal code doesn't match the format of
+// its country.
+var ErrInvalidPostalCode = errors.New("Invalid postal code")
+
+// A PostalCode is a postal code (e.g. a German Postleitzahl) as a string, so
+// leading zeros (like in "01067") are preserved. Use ParsePostalCode to obtain
+// a valid one.
+type PostalCode string
+
+// postalFormats lists the formats of the postal codes of some countries after
+// normalization, keyed by ISO 3166-1 alpha-2 country code.
+var postalFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"CZ": regexp.MustCompile(`^\d{3} \d{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? \d[A-Z]{2}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"LI": regexp.MustCompile(`^\d{4}$`),
+	"LU": regexp.MustCompile(`^\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} [A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} \d{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// ParsePostalCode parses the postal code of the country, which is an ISO 3166-1
+// alpha-2 country code. Surrounding whitespace is removed, letters are
+// converted to upper case and the separating space of Dutch, British, Czech and
+// Swedish postal codes is inserted if it is missing (e.g. "1012ab" becomes
+// "1012 AB"). An error wrapping ErrInvalidPostalCode is returned if the postal
+// code doesn't match the format of the country. Postal codes of countries which
+// aren't known are only checked for being non-empty.
+func ParsePostalCode(s, country string) (PostalCode, error) {
+	p := normalizePostalCode(s, strings.ToUpper(country))
+	if err := p.Validate(country); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// normalizePostalCode converts s to upper case, collapses whitespace and
+// inserts the separating space for the countries which use one.
+func normalizePostalCode(s, country string) PostalCode {
+	s = strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	compact := strings.Replace(s, " ", "", -1)
+	switch country {
+	case "GB":
+		if len(compact) >= 5 {
+			s = compact[:len(compact)-3] + " " + compact[len(compact)-3:]
+		}
+	case "NL":
+		if len(compact) == 6 {
+			s = compact[:4] + " " + compact[4:]
+		}
+	case "CZ", "SE":
+		if len(compact) == 5 {
+			s = compact[:3] + " " + compact[3:]
+		}
+	}
+	return PostalCode(s)
+}
+
+// Validate checks the postal code against the format of the country, which is
+// an ISO 3166-1 alpha-2 country code. The postal code must be normalized (see
+// ParsePostalCode).
+func (p PostalCode) Validate(country string) error {
+	if p == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidPostalCode)
+	}
+	country = strings.ToUpper(country)
+	if f, ok := postalFormats[country]; ok && !f.MatchString(string(p)) {
+		return fmt.Errorf("%w %q for country %s", ErrInvalidPostalCode, string(p), country)
+	}
+	return nil
+}
+
+func (p PostalCode) String() string {
+	return string(p)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a JSON
+// string or number. Numbers are taken literally, so leading zeros which got
+// lost by encoding a postal code as number can't be restored.
+func (p *PostalCode) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalStringOrNumber(b)
+	if err != nil {
+		return err
+	}
+	*p = PostalCode(strings.TrimSpace(s))
+	return nil
+}
+
+// A HouseNumber is the house number of an address. Number is the numeric part
+// as written and Suffix everything after it, including a separator, like the
+// letter of "12a", " a" of "12 a" or the range of "12-14". So a house number is
+// written exactly as it was parsed. House numbers without a numeric part (e.g.
+// "ohne Nummer") are kept in Suffix with Number being empty, which tells them
+// apart from the number "0".
+type HouseNumber struct {
+	Number string
+	Suffix string
+}
+
+// ParseHouseNumber splits a house number like "12a" or "12 a" into its number
+// and suffix. Surrounding whitespace is removed. It never fails: a house number
+// which doesn't start with digits is kept as suffix.
+func ParseHouseNumber(s string) HouseNumber {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return HouseNumber{Number: s[:i], Suffix: s[i:]}
+}
+
+// String returns the house number as it is written in an address (e.g. "12a"
+// or "12-14"). The zero house number is returned as empty string.
+func (h HouseNumber) String() string {
+	return h.Number + h.Suffix
+}
+
+// IsZero reports whether the house number is empty.
+func (h HouseNumber) IsZero() bool {
+	return h == HouseNumber{}
+}
+
+// MarshalJSON implements the json.Marshaler interface. The house number is
+// encoded as JSON string.
+func (h HouseNumber) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a JSON
+// string or number.
+func (h *HouseNumber) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalStringOrNumber(b)
+	if err != nil {
+		return err
+	}
+	*h = ParseHouseNumber(s)
+	return nil
+}
+
+// unmarshalStringOrNumber decodes a JSON string or the literal text of a JSON
+// number. null is decoded as empty string.
+func unmarshalStringOrNumber(b []byte) (string, error) {
+	b = bytes.TrimSpace(b)
+	switch {
+	case string(b) == "null":
+		return "", nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		err := json.Unmarshal(b, &s)
+		return s, err
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
@@ -0,0 +1,83 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParsePostalCode(t *testing.T) {
+	mockData := []struct {
+		s       string
+		country string
+		exp     PostalCode
+		valid   bool
+	}{
+		{"01067", "DE", "01067", true},
+		{" 60311 ", "de", "60311", true},
+		{"1067", "DE", "", false},
+		{"8001", "CH", "8001", true},
+		{"1012ab", "NL", "1012 AB", true},
+		{"sw1a2aa", "GB", "SW1A 2AA", true},
+		{"00-950", "PL", "00-950", true},
+		{"11455", "SE", "114 55", true},
+		{"12345-6789", "US", "12345-6789", true},
+		{"ABC", "XX", "ABC", true},
+		{"", "XX", "", false},
+	}
+
+	for _, tt := range mockData {
+		act, err := ParsePostalCode(tt.s, tt.country)
+		if !tt.valid {
+			assert(t, errors.Is(err, ErrInvalidPostalCode), "expected ErrInvalidPostalCode for %q, got %v", tt.s, err)
+			continue
+		}
+		ok(t, err)
+		equals(t, tt.exp, act)
+	}
+}
+
+func TestParseHouseNumber(t *testing.T) {
+	mockData := []struct {
+		s   string
+		exp HouseNumber
+		str string
+	}{
+		{"19", HouseNumber{Number: "19"}, "19"},
+		{"12a", HouseNumber{"12", "a"}, "12a"},
+		{" 12 a ", HouseNumber{"12", " a"}, "12 a"},
+		{"0", HouseNumber{Number: "0"}, "0"},
+		{"12-14", HouseNumber{"12", "-14"}, "12-14"},
+		{"ohne Nummer", HouseNumber{Suffix: "ohne Nummer"}, "ohne Nummer"},
+		{"", HouseNumber{}, ""},
+	}
+
+	for _, tt := range mockData {
+		act := ParseHouseNumber(tt.s)
+		equals(t, tt.exp, act)
+		equals(t, tt.str, act.String())
+	}
+}
+
+func TestHouseNumber_JSON(t *testing.T) {
+	var h HouseNumber
+	ok(t, json.Unmarshal([]byte(`"12a"`), &h))
+	equals(t, HouseNumber{"12", "a"}, h)
+	ok(t, json.Unmarshal([]byte(`7`), &h))
+	equals(t, HouseNumber{Number: "7"}, h)
+
+	b, err := json.Marshal(HouseNumber{"12", "a"})
+	ok(t, err)
+	equals(t, `"12a"`, string(b))
+}
+
+func TestPostalCode_UnmarshalJSON(t *testing.T) {
+	var p PostalCode
+	ok(t, json.Unmarshal([]byte(`"01067"`), &p))
+	equals(t, PostalCode("01067"), p)
+	ok(t, json.Unmarshal([]byte(`60311`), &p))
+	equals(t, PostalCode("60311"), p)
+
+	err := json.Unmarshal([]byte(`{}`), &p)
+	assert(t, err != nil, "expected error for object")
+}
@@ -0,0 +1,183 @@
+package dbapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrInvalidOrder is raised when a processing order is invalid (e.g. nil or
+// without ID).
+var ErrInvalidOrder = errors.New("Invalid processing order")
+
+// The ProcessingOrdersService binds to the HTTP endpoints which belong to the
+// processingOrders resource.
+type ProcessingOrdersService struct {
+	client *Client
+}
+
+// OrderType is the type of a processing order.
+type OrderType string
+
+// Available types of processing orders.
+const (
+	// SEPACreditTransfer is a SEPA credit transfer (SEPA-Überweisung).
+	SEPACreditTransfer OrderType = "SEPA_CREDIT_TRANSFER"
+	// SEPAInstantCreditTransfer is a SEPA instant credit transfer
+	// (Echtzeitüberweisung).
+	SEPAInstantCreditTransfer OrderType = "SEPA_INSTANT_CREDIT_TRANSFER"
+)
+
+// OrderStatus is the processing status of an order.
+type OrderStatus string
+
+// Possible processing statuses of an order.
+const (
+	// OrderChallengeRequired means the order has to be authorized with an
+	// OTP/TAN before it is executed (see ProcessingOrdersService.Authorize).
+	OrderChallengeRequired OrderStatus = "CHALLENGE_REQUIRED"
+	// OrderAccepted means the order has been authorized and awaits execution.
+	OrderAccepted OrderStatus = "ACCEPTED"
+	// OrderExecuted means the order has been executed.
+	OrderExecuted OrderStatus = "EXECUTED"
+	// OrderRejected means the order has been rejected by the bank.
+	OrderRejected OrderStatus = "REJECTED"
+	// OrderCanceled means the order has been canceled.
+	OrderCanceled OrderStatus = "CANCELED"
+)
+
+// A ProcessingOrderRequest describes a processing order (e.g. a SEPA credit
+// transfer) which is submitted to the bank. If Currency is empty, the currency
+// of Amount is submitted.
+type ProcessingOrderRequest struct {
+	Type                   OrderType `json:"type,omitempty"`
+	DebtorIBAN             IBAN      `json:"debtorIban,omitempty"`
+	CreditorName           string    `json:"creditorName,omitempty"`
+	CreditorIBAN           IBAN      `json:"creditorIban,omitempty"`
+	CreditorBIC            string    `json:"creditorBic,omitempty"`
+	Amount                 Money     `json:"amount"`
+	Currency               Currency  `json:"currency,omitempty"`
+	RemittanceInformation  string    `json:"remittanceInformation,omitempty"`
+	EndToEndID             string    `json:"endToEndId,omitempty"`
+	RequestedExecutionDate *Date     `json:"requestedExecutionDate,omitempty"`
+}
+
+// A ProcessingOrder is a processing order as returned by the bank.
+type ProcessingOrder struct {
+	ProcessingOrderRequest
+
+	ID        string      `json:"id,omitempty"`
+	Status    OrderStatus `json:"status,omitempty"`
+	Challenge *Challenge  `json:"challenge,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The amount is
+// decoded in the currency of the order instead of DefaultCurrency.
+func (o *ProcessingOrder) UnmarshalJSON(b []byte) error {
+	type order ProcessingOrder
+	var raw struct {
+		*order
+		Amount json.RawMessage `json:"amount"`
+	}
+	raw.order = (*order)(o)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw.Amount == nil {
+		return nil
+	}
+	o.Amount = NewMoney(0, o.Currency)
+	return o.Amount.UnmarshalJSON(raw.Amount)
+}
+
+// A Challenge must be solved with an OTP/TAN to authorize a processing order.
+type Challenge struct {
+	ID string `json:"id,omitempty"`
+	// Method is the method by which the OTP/TAN is delivered to the user (e.g.
+	// PHOTO_TAN or SMS_TAN).
+	Method string `json:"method,omitempty"`
+	// Text is the text of the challenge which is presented to the user.
+	Text string `json:"text,omitempty"`
+}
+
+// challengeResponse is the answer to a challenge.
+type challengeResponse struct {
+	ChallengeID string `json:"challengeId"`
+	OTP         string `json:"otp"`
+}
+
+// Submit submits a new processing order. Usually the returned order has the
+// status OrderChallengeRequired and has to be authorized by using Authorize. If
+// the IBAN of the debtor or creditor is invalid, an error wrapping
+// ErrInvalidIBAN is returned without calling the API. The checksum of the
+// debtor IBAN, which is an account of the user, isn't checked (see
+// IBAN.ValidateStructure).
+// Submit is never retried, even if a retry policy is set, unless the policy
+// allows retrying non-idempotent requests.
+func (s *ProcessingOrdersService) Submit(order *ProcessingOrderRequest) (*ProcessingOrder, *Response, error) {
+	return s.SubmitContext(context.Background(), order)
+}
+
+// SubmitContext is like Submit but uses the context ctx for the request.
+func (s *ProcessingOrdersService) SubmitContext(ctx context.Context, order *ProcessingOrderRequest) (*ProcessingOrder, *Response, error) {
+	if order == nil {
+		return nil, nil, ErrInvalidOrder
+	}
+	o := *order
+	var err error
+	if o.DebtorIBAN, err = parseRequestIBAN(o.DebtorIBAN); err != nil {
+		return nil, nil, err
+	}
+	if o.CreditorIBAN, err = ParseIBAN(string(o.CreditorIBAN)); err != nil {
+		return nil, nil, err
+	}
+	if o.Currency == "" {
+		o.Currency = o.Amount.Currency()
+	}
+	order = &o
+	u := "/processingOrders"
+	r := new(ProcessingOrder)
+
+	resp, err := s.client.CallContext(ctx, http.MethodPost, u, order, r)
+	return r, resp, err
+}
+
+// Get reads the processing order with the given ID to retrieve its status.
+func (s *ProcessingOrdersService) Get(id string) (*ProcessingOrder, *Response, error) {
+	return s.GetContext(context.Background(), id)
+}
+
+// GetContext is like Get but uses the context ctx for the request.
+func (s *ProcessingOrdersService) GetContext(ctx context.Context, id string) (*ProcessingOrder, *Response, error) {
+	if id == "" {
+		return nil, nil, ErrInvalidOrder
+	}
+	u := fmt.Sprintf("/processingOrders/%s", url.PathEscape(id))
+	r := new(ProcessingOrder)
+
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
+	return r, resp, err
+}
+
+// Authorize solves the challenge of the processing order with the OTP/TAN the
+// user received. The updated order is returned.
+func (s *ProcessingOrdersService) Authorize(order *ProcessingOrder, otp string) (*ProcessingOrder, *Response, error) {
+	return s.AuthorizeContext(context.Background(), order, otp)
+}
+
+// AuthorizeContext is like Authorize but uses the context ctx for the request.
+func (s *ProcessingOrdersService) AuthorizeContext(ctx context.Context, order *ProcessingOrder, otp string) (*ProcessingOrder, *Response, error) {
+	if order == nil || order.ID == "" || order.Challenge == nil {
+		return nil, nil, ErrInvalidOrder
+	}
+	u := fmt.Sprintf("/processingOrders/%s/challenge", url.PathEscape(order.ID))
+	b := &challengeResponse{ChallengeID: order.Challenge.ID, OTP: otp}
+	r := new(ProcessingOrder)
+
+	resp, err := s.client.CallContext(ctx, http.MethodPost, u, b, r)
+	return r, resp, err
+}
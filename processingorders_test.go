@@ -0,0 +1,133 @@
+package dbapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestProcessingOrdersService_Submit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	order := &ProcessingOrderRequest{
+		Type:                  SEPACreditTransfer,
+		DebtorIBAN:            "DE10000000000000000454",
+		CreditorName:          "Claudia Klar",
+		CreditorIBAN:          "DE89370400440532013000",
+		Amount:                eur("50"),
+		RemittanceInformation: "Sparen Samuel",
+	}
+	submitted := *order
+	submitted.Currency = EUR
+	exp := &ProcessingOrder{
+		ProcessingOrderRequest: submitted,
+		ID:                     "4711",
+		Status:                 OrderChallengeRequired,
+		Challenge:              &Challenge{ID: "c-1", Method: "PHOTO_TAN", Text: "Bitte TAN eingeben"},
+	}
+
+	testMux.HandleFunc("/v1/processingOrders", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, http.MethodPost, r.Method)
+		body, _ := ioutil.ReadAll(r.Body)
+		equals(t, `{"type":"SEPA_CREDIT_TRANSFER","debtorIban":"DE10000000000000000454","creditorName":"Claudia Klar","creditorIban":"DE89370400440532013000","amount":50.00,"currency":"EUR","remittanceInformation":"Sparen Samuel"}`+"\n", string(body))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"4711","status":"CHALLENGE_REQUIRED","type":"SEPA_CREDIT_TRANSFER","debtorIban":"DE10000000000000000454","creditorName":"Claudia Klar","creditorIban":"DE89370400440532013000","amount":50,"currency":"EUR","remittanceInformation":"Sparen Samuel","challenge":{"id":"c-1","method":"PHOTO_TAN","text":"Bitte TAN eingeben"}}`)
+	})
+
+	act, _, err := testClient.ProcessingOrders.Submit(order)
+	ok(t, err)
+	equals(t, exp, act)
+
+	_, _, err = testClient.ProcessingOrders.Submit(nil)
+	equals(t, ErrInvalidOrder, err)
+}
+
+func TestProcessingOrdersService_Submit_InvalidCreditorIBAN(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/processingOrders", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be made.")
+	})
+
+	// The checksum of the creditor IBAN is wrong.
+	order := &ProcessingOrderRequest{
+		Type:         SEPACreditTransfer,
+		DebtorIBAN:   "DE10000000000000000454",
+		CreditorName: "Claudia Klar",
+		CreditorIBAN: "DE89370400440532013001",
+		Amount:       eur("50"),
+	}
+	_, resp, err := testClient.ProcessingOrders.Submit(order)
+	assert(t, errors.Is(err, ErrInvalidIBAN), "Expected ErrInvalidIBAN, got %v.", err)
+	assert(t, resp == nil, "Expected no response.")
+}
+
+func TestProcessingOrdersService_Get(t *testing.T) {
+	setup()
+	defer teardown()
+
+	exp := &ProcessingOrder{ID: "4711", Status: OrderExecuted}
+
+	testMux.HandleFunc("/v1/processingOrders/4711", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"id":"4711","status":"EXECUTED"}`)
+	})
+
+	act, _, err := testClient.ProcessingOrders.Get("4711")
+	ok(t, err)
+	equals(t, exp, act)
+
+	_, _, err = testClient.ProcessingOrders.Get("")
+	equals(t, ErrInvalidOrder, err)
+}
+
+func TestProcessingOrder_UnmarshalJSON(t *testing.T) {
+	mockData := []struct {
+		json string
+		exp  Money
+	}{
+		{`{"amount":50.5,"currency":"CHF"}`, NewMoney(5050, CHF)},
+		{`{"currency":"JPY","amount":1500}`, NewMoney(1500, JPY)},
+		{`{"amount":50}`, eur("50")},
+	}
+
+	for _, tt := range mockData {
+		var o ProcessingOrder
+		ok(t, json.Unmarshal([]byte(tt.json), &o))
+		equals(t, tt.exp, o.Amount)
+	}
+
+	var o ProcessingOrder
+	ok(t, json.Unmarshal([]byte(`{"id":"4711","status":"EXECUTED"}`), &o))
+	equals(t, ProcessingOrder{ID: "4711", Status: OrderExecuted}, o)
+
+	err := json.Unmarshal([]byte(`{"amount":12.5,"currency":"JPY"}`), &o)
+	assert(t, errors.Is(err, ErrInvalidAmount), "Expected ErrInvalidAmount, got %v.", err)
+}
+
+func TestProcessingOrdersService_Authorize(t *testing.T) {
+	setup()
+	defer teardown()
+
+	order := &ProcessingOrder{ID: "4711", Status: OrderChallengeRequired, Challenge: &Challenge{ID: "c-1"}}
+	exp := &ProcessingOrder{ID: "4711", Status: OrderAccepted}
+
+	testMux.HandleFunc("/v1/processingOrders/4711/challenge", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, http.MethodPost, r.Method)
+		body, _ := ioutil.ReadAll(r.Body)
+		equals(t, `{"challengeId":"c-1","otp":"123456"}`+"\n", string(body))
+		fmt.Fprint(w, `{"id":"4711","status":"ACCEPTED"}`)
+	})
+
+	act, _, err := testClient.ProcessingOrders.Authorize(order, "123456")
+	ok(t, err)
+	equals(t, exp, act)
+
+	_, _, err = testClient.ProcessingOrders.Authorize(&ProcessingOrder{ID: "4711"}, "123456")
+	equals(t, ErrInvalidOrder, err)
+}
@@ -0,0 +1,182 @@
+package dbapi
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CardPayment is the kind of a card payment at a point of sale, as stated in
+// the usage of a transaction.
+type CardPayment string
+
+// Recognized kinds of card payments.
+const (
+	// CardWithPIN is a card payment authorized with a PIN (girocard).
+	CardWithPIN CardPayment = "POS MIT PIN"
+	// CardWithoutPIN is a card payment authorized with a signature, which is
+	// settled as direct debit (elektronisches Lastschriftverfahren).
+	CardWithoutPIN CardPayment = "POS OHNE PIN"
+)
+
+func (c CardPayment) String() string {
+	return string(c)
+}
+
+// A Remittance is the structured information (Verwendungszweck) of a
+// transaction, which is parsed from its usage by ParseRemittance. Fields whose
+// tags aren't present are empty.
+type Remittance struct {
+	// EndToEndReference is the reference the originator assigned to the
+	// payment (EREF+).
+	EndToEndReference string
+	// CustomerReference is the reference of the customer (KREF+).
+	CustomerReference string
+	// MandateReference is the reference of the direct debit mandate (MREF+).
+	MandateReference string
+	// CreditorID is the SEPA creditor identifier of a direct debit (CRED+).
+	CreditorID string
+	// Purpose is the free text of the remittance (SVWZ+), including text which
+	// isn't tagged.
+	Purpose string
+	// UltimateDebtor is the party on whose behalf the payment was made, if it
+	// differs from the debtor (ABWA+).
+	UltimateDebtor string
+	// UltimateCreditor is the party on whose behalf the payment was received,
+	// if it differs from the creditor (ABWE+).
+	UltimateCreditor string
+	// Card is the kind of card payment, if the transaction is a card payment
+	// at a point of sale.
+	Card CardPayment
+}
+
+// remittanceTags maps the SEPA remittance tags to the fields they set. Tags
+// which aren't listed (e.g. IBAN+ or COAM+) are recognized as field boundaries
+// but their values are dropped.
+var remittanceTags = map[string]func(r *Remittance) *string{
+	"EREF": func(r *Remittance) *string { return &r.EndToEndReference },
+	"KREF": func(r *Remittance) *string { return &r.CustomerReference },
+	"MREF": func(r *Remittance) *string { return &r.MandateReference },
+	"CRED": func(r *Remittance) *string { return &r.CreditorID },
+	"SVWZ": func(r *Remittance) *string { return &r.Purpose },
+	"ABWA": func(r *Remittance) *string { return &r.UltimateDebtor },
+	"ABWE": func(r *Remittance) *string { return &r.UltimateCreditor },
+}
+
+// remittanceTagNames are all tags which are recognized as field boundaries.
+var remittanceTagNames = []string{
+	"EREF", "KREF", "MREF", "CRED", "SVWZ", "ABWA", "ABWE", "DEBT", "COAM",
+	"OAMT", "IBAN", "BIC",
+}
+
+var (
+	remittanceTagRe = regexp.MustCompile(`(` + strings.Join(remittanceTagNames, "|") + `)\+`)
+	cardPaymentRe   = regexp.MustCompile(`^(POS (?:MIT|OHNE) PIN)\.?\s*`)
+)
+
+// remittanceLineWidths are the widths at which banks wrap the usage, so lines
+// of these widths are continued on the next line without a space.
+var remittanceLineWidths = map[int]bool{27: true, 35: true, 140: true}
+
+// remittanceLimits are the lengths at which banks truncate the usage: 140
+// characters of SEPA and 14 lines of 27 characters of the former DTAUS format.
+var remittanceLimits = map[int]bool{140: true, 378: true}
+
+// notProvided is the value of a tag which the originator didn't fill.
+const notProvided = "NOTPROVIDED"
+
+// ParseRemittance parses the usage of a transaction into its SEPA remittance
+// tags (e.g. "EREF+123 SVWZ+Rechnung 42"). Text which precedes the first tag is
+// part of the purpose, as is the text following a card payment marker like
+// "POS MIT PIN.".
+//
+// The parser is tolerant of the ways usages are mangled: lines wrapped at 27 or
+// 35 characters are joined without a space, other line breaks are replaced by a
+// space, and a tag which is cut off at the end of a truncated usage is dropped.
+// A usage is considered truncated if it has 140 or 378 characters; the last
+// word of other usages is kept even if it looks like the beginning of a tag
+// (e.g. "BIC"). Values equal to NOTPROVIDED are treated as missing.
+func ParseRemittance(usage string) Remittance {
+	var r Remittance
+	s := unwrapUsage(usage)
+	truncated := remittanceLimits[utf8.RuneCountInString(strings.NewReplacer("\r", "", "\n", "").Replace(usage))]
+
+	if m := cardPaymentRe.FindStringSubmatch(s); m != nil {
+		r.Card = CardPayment(m[1])
+		s = s[len(m[0]):]
+	}
+	locs := remittanceTagRe.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) > 0 && truncated {
+		s = trimPartialTag(s)
+		locs = remittanceTagRe.FindAllStringSubmatchIndex(s, -1)
+	}
+
+	end := len(s)
+	if len(locs) > 0 {
+		end = locs[0][0]
+	}
+	appendField(&r.Purpose, s[:end])
+	for i, loc := range locs {
+		end := len(s)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		field, ok := remittanceTags[s[loc[2]:loc[3]]]
+		if !ok {
+			continue
+		}
+		appendField(field(&r), s[loc[1]:end])
+	}
+	return r
+}
+
+// Remittance parses the usage of the transaction (see ParseRemittance).
+func (t Transaction) Remittance() Remittance {
+	return ParseRemittance(t.Usage)
+}
+
+// unwrapUsage joins the lines of the usage. Lines which fill one of the usual
+// line widths are joined without a space, because the bank wrapped them in the
+// middle of a word.
+func unwrapUsage(usage string) string {
+	lines := strings.Split(strings.Replace(usage, "\r\n", "\n", -1), "\n")
+	var b strings.Builder
+	for i, l := range lines {
+		b.WriteString(l)
+		if i < len(lines)-1 && !remittanceLineWidths[utf8.RuneCountInString(l)] {
+			b.WriteByte(' ')
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// trimPartialTag removes the beginning of a tag (e.g. " SVW" or " EREF") from
+// the end of a truncated usage. It is only applied to usages which contain
+// tags, so ordinary words at the end of a free text usage are kept.
+func trimPartialTag(s string) string {
+	i := strings.LastIndexByte(s, ' ')
+	last := s[i+1:]
+	if last == "" {
+		return s
+	}
+	for _, tag := range remittanceTagNames {
+		if strings.HasPrefix(tag+"+", last) && last != tag+"+" {
+			return strings.TrimSpace(s[:i+1])
+		}
+	}
+	return s
+}
+
+// appendField appends the value to the field, separated by a space. Repeated
+// tags, which occur when a field is split across lines, are thereby joined.
+func appendField(field *string, value string) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "" || value == notProvided:
+		return
+	case *field == "":
+		*field = value
+	default:
+		*field += " " + value
+	}
+}
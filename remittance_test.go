@@ -0,0 +1,105 @@
+package dbapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRemittance(t *testing.T) {
+	mockData := []struct {
+		usage string
+		exp   Remittance
+	}{
+		{
+			"Rechnung",
+			Remittance{Purpose: "Rechnung"},
+		},
+		{
+			"POS MIT PIN. Einkauf",
+			Remittance{Card: CardWithPIN, Purpose: "Einkauf"},
+		},
+		{
+			"POS OHNE PIN Die Tanke Ihrer Wahl",
+			Remittance{Card: CardWithoutPIN, Purpose: "Die Tanke Ihrer Wahl"},
+		},
+		{
+			"EREF+E2E-4711 MREF+M-0815 CRED+DE98ZZZ09999999999 SVWZ+Beitrag Oktober ABWA+Max Mustermann",
+			Remittance{
+				EndToEndReference: "E2E-4711",
+				MandateReference:  "M-0815",
+				CreditorID:        "DE98ZZZ09999999999",
+				Purpose:           "Beitrag Oktober",
+				UltimateDebtor:    "Max Mustermann",
+			},
+		},
+		{
+			"KREF+K1 EREF+NOTPROVIDED SVWZ+Miete ABWE+Hausverwaltung IBAN+DE89370400440532013000",
+			Remittance{CustomerReference: "K1", Purpose: "Miete", UltimateCreditor: "Hausverwaltung"},
+		},
+		{
+			// Wrapped at 27 characters in the middle of a word.
+			"EREF+12345 SVWZ+Rechnung Nr\n. 42 vom 01.10.",
+			Remittance{EndToEndReference: "12345", Purpose: "Rechnung Nr. 42 vom 01.10."},
+		},
+		{
+			// Wrapped at a word boundary.
+			"SVWZ+Rechnung\nNr. 42",
+			Remittance{Purpose: "Rechnung Nr. 42"},
+		},
+		{
+			// Value split across lines of 27 characters.
+			"EREF+ABCDEFGHIJKLMNOPQRSTUV\nW SVWZ+Test",
+			Remittance{EndToEndReference: "ABCDEFGHIJKLMNOPQRSTUVW", Purpose: "Test"},
+		},
+		{
+			// Truncated at 140 characters in the middle of a tag.
+			"EREF+123 SVWZ+" + strings.Repeat("x", 122) + " ABW",
+			Remittance{EndToEndReference: "123", Purpose: strings.Repeat("x", 122)},
+		},
+		{
+			// Truncated in the middle of a value.
+			"EREF+123 SVWZ+Rechn",
+			Remittance{EndToEndReference: "123", Purpose: "Rechn"},
+		},
+		{
+			// Words which are prefixes of tags are kept unless the usage is
+			// truncated.
+			"EREF+123 SVWZ+Miete Wohnung DE",
+			Remittance{EndToEndReference: "123", Purpose: "Miete Wohnung DE"},
+		},
+		{
+			"SVWZ+Zahlung an Firma BIC",
+			Remittance{Purpose: "Zahlung an Firma BIC"},
+		},
+		{
+			"SVWZ+Zahlung auf neue IBAN",
+			Remittance{Purpose: "Zahlung auf neue IBAN"},
+		},
+		{
+			"EREF+123 SVWZ+Rechnung 42 ABW",
+			Remittance{EndToEndReference: "123", Purpose: "Rechnung 42 ABW"},
+		},
+		{
+			// Truncated at 140 characters in the middle of a tag.
+			"EREF+123 SVWZ+" + strings.Repeat("x", 123) + " DE",
+			Remittance{EndToEndReference: "123", Purpose: strings.Repeat("x", 123)},
+		},
+		{
+			"Einkauf bei SVW",
+			Remittance{Purpose: "Einkauf bei SVW"},
+		},
+		{
+			"",
+			Remittance{},
+		},
+	}
+
+	for _, tt := range mockData {
+		equals(t, tt.exp, ParseRemittance(tt.usage))
+	}
+}
+
+func TestTransaction_Remittance(t *testing.T) {
+	tx := Transaction{Usage: "POS MIT PIN. Einkauf"}
+	equals(t, Remittance{Card: CardWithPIN, Purpose: "Einkauf"}, tx.Remittance())
+}
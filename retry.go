@@ -0,0 +1,238 @@
+package dbapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidRetryPolicy is raised when a RetryPolicy is invalid (e.g. negative
+// attempts or backoff durations).
+var ErrInvalidRetryPolicy = errors.New("Invalid retry policy")
+
+// DefaultRetryPolicy is a sensible RetryPolicy for most applications. It is not
+// applied by default, use SetRetryPolicy to enable it.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.2,
+}
+
+// A RetryPolicy describes if and how failed requests are retried. A request is
+// retried if the http client returned an error or the API responded with one of
+// the status codes 429, 500, 502, 503 or 504. By default only idempotent
+// requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts (including the first one)
+	// that are made. A value of 0 or 1 disables retries.
+	MaxAttempts int
+	// MinBackoff is the backoff before the first retry. It is doubled for
+	// each subsequent retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the backoff between two attempts. If the API asks to wait
+	// longer than MaxBackoff (by sending a Retry-After header) the request is
+	// not retried. A value of 0 means no limit.
+	MaxBackoff time.Duration
+	// Jitter is the fraction (between 0 and 1) by which the backoff is randomly
+	// shortened or lengthened to spread out retries of concurrent clients.
+	Jitter float64
+	// RetryNonIdempotent also retries requests which are not idempotent (e.g.
+	// POST requests). Use with care.
+	RetryNonIdempotent bool
+}
+
+// An Attempt describes a single attempt to send a request.
+type Attempt struct {
+	// StatusCode is the status code of the response or 0 if no response was
+	// received.
+	StatusCode int
+	// Err is the error returned by the http client, if any.
+	Err error
+	// Backoff is the time that has been waited before the attempt was made.
+	Backoff time.Duration
+}
+
+// SetRetryPolicy specifies the policy for retrying failed requests. An error
+// ErrInvalidRetryPolicy is returned if the policy is invalid.
+func SetRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error { return c.setRetryPolicy(policy) }
+}
+func (c *Client) setRetryPolicy(policy RetryPolicy) error {
+	if policy.MaxAttempts < 0 || policy.MinBackoff < 0 || policy.MaxBackoff < 0 ||
+		policy.Jitter < 0 || policy.Jitter > 1 {
+		return ErrInvalidRetryPolicy
+	}
+	c.retryPolicy = policy
+	return nil
+}
+
+// retryMiddleware returns a Middleware which retries failed requests according
+// to the retry policy. Every attempt is recorded in the attempt log carried by
+// the context of the request, if present.
+func retryMiddleware(policy RetryPolicy) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			log, _ := req.Context().Value(attemptLogKey{}).(*[]Attempt)
+			if log == nil {
+				log = new([]Attempt)
+			}
+
+			var backoff time.Duration
+			for n := 1; ; n++ {
+				attemptReq := req
+				if n > 1 {
+					var err error
+					if attemptReq, err = rewindRequest(req); err != nil {
+						return nil, err
+					}
+				}
+
+				resp, err := next.RoundTrip(attemptReq)
+				attempt := Attempt{Err: err, Backoff: backoff}
+				if resp != nil {
+					attempt.StatusCode = resp.StatusCode
+				}
+				*log = append(*log, attempt)
+
+				if n >= policy.MaxAttempts || !policy.retryable(req, resp, err) {
+					return resp, err
+				}
+				var ok bool
+				if backoff, ok = policy.backoff(n, resp); !ok {
+					return resp, err
+				}
+
+				// The response of the failed attempt is discarded.
+				if resp != nil {
+					io.Copy(ioutil.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				if err := sleep(req.Context(), backoff); err != nil {
+					return nil, err
+				}
+			}
+		})
+	}
+}
+
+// attemptLogKey is the context key of the attempt log which is filled by the
+// retry middleware.
+type attemptLogKey struct{}
+
+// retryable reports whether the request should be retried, based on the result
+// of the previous attempt.
+func (p RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(req.Method) {
+		return false
+	}
+	if !replayable(req) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the time to wait before attempt n+1. A Retry-After header sent
+// with the response takes precedence over the exponential backoff. False is
+// returned if the API asks to wait longer than MaxBackoff.
+func (p RetryPolicy) backoff(n int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d, p.MaxBackoff == 0 || d <= p.MaxBackoff
+		}
+	}
+
+	d := float64(p.MinBackoff) * math.Pow(2, float64(n-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d), true
+}
+
+// parseRetryAfter parses the value of a Retry-After header which is either a
+// number of seconds or a HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// isIdempotent reports whether requests with the HTTP method m are idempotent
+// as defined by RFC 7231.
+func isIdempotent(m string) bool {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// replayable reports whether the request can be sent again. A request with a
+// body can only be sent again if the body can be replayed.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns a copy of the request with a fresh body, so it can be
+// sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// sleep waits for the duration d or until the context is done. In the latter
+// case the error of the context is returned.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,185 @@
+package dbapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly to keep the tests fast.
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  10 * time.Millisecond,
+}
+
+func TestSetRetryPolicy(t *testing.T) {
+	mockData := []struct {
+		Policy        RetryPolicy
+		ExpectedError error
+	}{
+		{DefaultRetryPolicy, nil},
+		{RetryPolicy{}, nil},
+		{RetryPolicy{MaxAttempts: -1}, ErrInvalidRetryPolicy},
+		{RetryPolicy{MinBackoff: -time.Second}, ErrInvalidRetryPolicy},
+		{RetryPolicy{Jitter: 1.5}, ErrInvalidRetryPolicy},
+	}
+
+	for _, mock := range mockData {
+		api, err := NewClient(
+			SetRetryPolicy(mock.Policy),
+		)
+		if api != nil {
+			equals(t, mock.Policy, api.retryPolicy)
+		}
+		equals(t, mock.ExpectedError, err)
+	}
+}
+
+func TestDo_Retry(t *testing.T) {
+	setup()
+	defer teardown()
+	ok(t, testClient.Options(SetRetryPolicy(testRetryPolicy)))
+
+	calls := 0
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"A":"a"}`)
+		}
+	})
+
+	req, _ := testClient.NewRequest(http.MethodGet, "/", nil)
+	resp, err := testClient.Do(req, nil)
+	ok(t, err)
+
+	equals(t, 3, calls)
+	equals(t, 3, len(resp.Attempts))
+	equals(t, http.StatusServiceUnavailable, resp.Attempts[0].StatusCode)
+	equals(t, http.StatusTooManyRequests, resp.Attempts[1].StatusCode)
+	equals(t, http.StatusOK, resp.Attempts[2].StatusCode)
+	equals(t, time.Duration(0), resp.Attempts[2].Backoff)
+}
+
+func TestDo_RetryExhausted(t *testing.T) {
+	setup()
+	defer teardown()
+	ok(t, testClient.Options(SetRetryPolicy(testRetryPolicy)))
+
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req, _ := testClient.NewRequest(http.MethodGet, "/", nil)
+	resp, err := testClient.Do(req, nil)
+
+	assert(t, err != nil, "Expected error to be returned (expected HTTP 502 error).")
+	equals(t, 3, len(resp.Attempts))
+}
+
+func TestDo_RetryTransportError(t *testing.T) {
+	setup()
+	defer teardown()
+	ok(t, testClient.Options(SetRetryPolicy(testRetryPolicy)))
+
+	// Close the connection without sending a response.
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		ok(t, err)
+		conn.Close()
+	})
+
+	req, _ := testClient.NewRequest(http.MethodGet, "/", nil)
+	resp, err := testClient.Do(req, nil)
+
+	assert(t, err != nil, "Expected error to be returned (expected closed connection).")
+	assert(t, resp != nil, "Expected response carrying the attempts.")
+	assert(t, resp.Response == nil, "Expected no HTTP response.")
+	equals(t, 3, len(resp.Attempts))
+	for _, a := range resp.Attempts {
+		assert(t, a.Err != nil, "Expected error of attempt to be recorded.")
+		equals(t, 0, a.StatusCode)
+	}
+}
+
+func TestDo_RetryNonIdempotent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var bodies []string
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	// POST requests aren't retried by default.
+	ok(t, testClient.Options(SetRetryPolicy(testRetryPolicy)))
+	req, _ := testClient.NewRequest(http.MethodPost, "/", &testRequest{ID: 1})
+	_, err := testClient.Do(req, nil)
+	assert(t, err != nil, "Expected error to be returned (expected HTTP 503 error).")
+	equals(t, 1, len(bodies))
+
+	// But they are if explicitly enabled and the body is replayed.
+	bodies = nil
+	policy := testRetryPolicy
+	policy.RetryNonIdempotent = true
+	ok(t, testClient.Options(SetRetryPolicy(policy)))
+	req, _ = testClient.NewRequest(http.MethodPost, "/", &testRequest{ID: 1})
+	_, err = testClient.Do(req, nil)
+	ok(t, err)
+	equals(t, 2, len(bodies))
+	equals(t, bodies[0], bodies[1])
+}
+
+func TestDo_RetryCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+	ok(t, testClient.Options(SetRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Minute,
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	testMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := testClient.CallContext(ctx, http.MethodGet, "/", nil, nil)
+	equals(t, context.Canceled, err)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockData := []struct {
+		Value            string
+		ExpectedDuration time.Duration
+		ExpectedOK       bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Wed, 01 Mar 2017 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 Mar 2017 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, mock := range mockData {
+		d, ok := parseRetryAfter(mock.Value, now)
+		equals(t, mock.ExpectedDuration, d)
+		equals(t, mock.ExpectedOK, ok)
+	}
+}
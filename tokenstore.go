@@ -0,0 +1,316 @@
+package dbapi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrTokenNotFound is returned by a TokenStore if there is no token stored
+	// for a key.
+	ErrTokenNotFound = errors.New("Token not found")
+	// ErrInvalidTokenStore is raised when the token store is invalid (e.g. nil).
+	ErrInvalidTokenStore = errors.New("Invalid token store")
+	// ErrInvalidPassphrase is raised when the passphrase of an encrypted token
+	// file is empty or wrong, or the file has been tampered with.
+	ErrInvalidPassphrase = errors.New("Invalid passphrase or corrupted token file")
+)
+
+// scrypt parameters used to derive the key of an encrypted token file from its
+// passphrase. See https://godoc.org/golang.org/x/crypto/scrypt.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// A TokenStore persists tokens, so they can be reused between runs. Tokens are
+// stored under a key which identifies the test user and the client (see
+// TokenKey), so several identities can share one store. Implementations must be
+// safe for concurrent use.
+type TokenStore interface {
+	// Load returns the token stored under key. ErrTokenNotFound is returned if
+	// there is none.
+	Load(key string) (*Token, error)
+	// Save stores the token under key, replacing any previous token.
+	Save(key string, tok *Token) error
+	// Delete removes the token stored under key. It is not an error if there
+	// is none.
+	Delete(key string) error
+}
+
+// TokenKey returns the key under which the token of the test user is stored
+// for the application with the client ID.
+func TokenKey(clientID, user string) string {
+	return clientID + "/" + user
+}
+
+// SetTokenStore specifies the store in which the tokens obtained by Exchange
+// and refreshed tokens are persisted under key. If the store already holds a
+// token for key, it is used to authenticate requests. An error
+// ErrInvalidTokenStore is returned if the passed store is nil.
+func SetTokenStore(store TokenStore, key string) Option {
+	return func(c *Client) error { return c.setTokenStore(store, key) }
+}
+func (c *Client) setTokenStore(store TokenStore, key string) error {
+	if store == nil {
+		return ErrInvalidTokenStore
+	}
+	s := c.Authentication
+	s.mu.Lock()
+	s.store, s.storeKey = store, key
+	s.mu.Unlock()
+
+	tok, err := store.Load(key)
+	if err == ErrTokenNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.token, s.oauth2Token = tok.AccessToken, tok
+	s.source = s.RefreshTokenSource(tok)
+	s.mu.Unlock()
+	return nil
+}
+
+// saveToken persists the token in the token store, if present.
+func (s *AuthenticationService) saveToken(tok *Token) error {
+	s.mu.RLock()
+	store, key := s.store, s.storeKey
+	s.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.Save(key, tok)
+}
+
+// MemoryTokenStore is a TokenStore which keeps the tokens in memory.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]Token
+}
+
+// NewMemoryTokenStore creates and returns a new, empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]Token)}
+}
+
+// Load implements the TokenStore interface.
+func (s *MemoryTokenStore) Load(key string) (*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tok, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &tok, nil
+}
+
+// Save implements the TokenStore interface.
+func (s *MemoryTokenStore) Save(key string, tok *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = *tok
+	return nil
+}
+
+// Delete implements the TokenStore interface.
+func (s *MemoryTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore is a TokenStore which keeps the tokens in a file. The file is
+// encrypted with AES-256-GCM by using a key which is derived from a passphrase
+// with scrypt. It is only readable and writable by its owner and replaced
+// atomically on every write, so a crash never leaves a corrupted file behind.
+type FileTokenStore struct {
+	path       string
+	passphrase []byte
+
+	mu   sync.Mutex
+	salt []byte
+	key  []byte
+}
+
+// tokenFile is the on-disk format of a FileTokenStore.
+type tokenFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// NewFileTokenStore creates and returns a new FileTokenStore which keeps the
+// tokens in the file at path. The file is created on the first write. An error
+// ErrInvalidPassphrase is returned if the passphrase is empty.
+func NewFileTokenStore(path, passphrase string) (*FileTokenStore, error) {
+	if passphrase == "" {
+		return nil, ErrInvalidPassphrase
+	}
+	return &FileTokenStore{path: path, passphrase: []byte(passphrase)}, nil
+}
+
+// Load implements the TokenStore interface.
+func (s *FileTokenStore) Load(key string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &tok, nil
+}
+
+// Save implements the TokenStore interface.
+func (s *FileTokenStore) Save(key string, tok *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[key] = *tok
+	return s.write(tokens)
+}
+
+// Delete implements the TokenStore interface.
+func (s *FileTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+	delete(tokens, key)
+	return s.write(tokens)
+}
+
+// read reads and decrypts all tokens from the file. A missing file is treated
+// as an empty one.
+func (s *FileTokenStore) read() (map[string]Token, error) {
+	tokens := make(map[string]Token)
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var f tokenFile
+	if err := json.Unmarshal(b, &f); err != nil || f.Version != 1 {
+		return nil, ErrInvalidPassphrase
+	}
+	aead, err := s.cipher(f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// write encrypts all tokens and atomically replaces the file with them.
+func (s *FileTokenStore) write(tokens map[string]Token) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	f := tokenFile{Version: 1, Salt: s.salt}
+	if f.Salt == nil {
+		f.Salt = make([]byte, 16)
+		if _, err := rand.Read(f.Salt); err != nil {
+			return err
+		}
+	}
+	aead, err := s.cipher(f.Salt)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = aead.Seal(nil, f.Nonce, data, nil)
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, b)
+}
+
+// cipher returns the AEAD cipher for the key derived from the passphrase and the
+// salt. The key of the last salt is cached since deriving it is expensive.
+func (s *FileTokenStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.key == nil || string(s.salt) != string(salt) {
+		key, err := scrypt.Key(s.passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+		if err != nil {
+			return nil, err
+		}
+		s.salt, s.key = salt, key
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes the data to a temporary file which is only accessible
+// by its owner and renames it to path afterwards.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	// Clean up in case of an error. After the rename this is a no-op.
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
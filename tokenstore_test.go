@@ -0,0 +1,131 @@
+package dbapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+}
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbapi")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tokens", "store.json")
+	store, err := NewFileTokenStore(path, "secret")
+	ok(t, err)
+	testTokenStore(t, store)
+
+	// The file is only accessible by its owner and no temporary files are
+	// left behind.
+	fi, err := os.Stat(path)
+	ok(t, err)
+	equals(t, os.FileMode(0600), fi.Mode().Perm())
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	ok(t, err)
+	equals(t, 1, len(files))
+
+	// The tokens aren't stored in plain text.
+	b, err := ioutil.ReadFile(path)
+	ok(t, err)
+	assert(t, !bytes.Contains(b, []byte("access-b")), "Expected token file to be encrypted.")
+
+	// Another store with the same passphrase can read the tokens.
+	other, err := NewFileTokenStore(path, "secret")
+	ok(t, err)
+	tok, err := other.Load(TokenKey("client", "b"))
+	ok(t, err)
+	equals(t, "access-b", tok.AccessToken)
+
+	// A wrong passphrase is detected.
+	wrong, err := NewFileTokenStore(path, "wrong")
+	ok(t, err)
+	_, err = wrong.Load(TokenKey("client", "b"))
+	equals(t, ErrInvalidPassphrase, err)
+
+	_, err = NewFileTokenStore(path, "")
+	equals(t, ErrInvalidPassphrase, err)
+}
+
+// testTokenStore tests the behaviour every TokenStore must implement.
+func testTokenStore(t *testing.T, store TokenStore) {
+	keyA, keyB := TokenKey("client", "a"), TokenKey("client", "b")
+	tokA := &Token{AccessToken: "access-a", RefreshToken: "refresh-a", Expiry: time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)}
+	tokB := &Token{AccessToken: "access-b", RefreshToken: "refresh-b"}
+
+	_, err := store.Load(keyA)
+	equals(t, ErrTokenNotFound, err)
+
+	ok(t, store.Save(keyA, tokA))
+	ok(t, store.Save(keyB, tokB))
+
+	tok, err := store.Load(keyA)
+	ok(t, err)
+	equals(t, tokA, tok)
+	tok, err = store.Load(keyB)
+	ok(t, err)
+	equals(t, tokB, tok)
+
+	ok(t, store.Delete(keyA))
+	ok(t, store.Delete(keyA))
+	_, err = store.Load(keyA)
+	equals(t, ErrTokenNotFound, err)
+}
+
+func TestSetTokenStore(t *testing.T) {
+	store := NewMemoryTokenStore()
+	key := TokenKey("client", "user")
+	tok := &Token{AccessToken: "stored", RefreshToken: "refresh"}
+	ok(t, store.Save(key, tok))
+
+	c, err := NewClient(
+		SetTokenStore(store, key),
+	)
+	ok(t, err)
+	equals(t, tok, c.Authentication.OAuth2Token())
+	equals(t, "stored", c.Authentication.Token())
+	assert(t, c.Authentication.HasAuth(), "Expected stored token to be used.")
+
+	c, err = NewClient(
+		SetTokenStore(store, TokenKey("client", "other")),
+	)
+	ok(t, err)
+	assert(t, !c.Authentication.HasAuth(), "Expected no token to be used.")
+
+	_, err = NewClient(
+		SetTokenStore(nil, key),
+	)
+	equals(t, ErrInvalidTokenStore, err)
+}
+
+func TestAuthenticationService_PersistTokens(t *testing.T) {
+	setupAuth()
+	defer teardown()
+
+	store := NewMemoryTokenStore()
+	key := TokenKey("client", "user")
+	ok(t, testClient.Options(SetTokenStore(store, key)))
+
+	pkce, err := NewPKCE()
+	ok(t, err)
+	testAuthServer.challenge = pkce.Challenge
+
+	_, err = testClient.Authentication.Exchange(testAuthCode, pkce)
+	ok(t, err)
+	tok, err := store.Load(key)
+	ok(t, err)
+	equals(t, "access-1", tok.AccessToken)
+
+	_, err = testClient.Authentication.Refresh(tok)
+	ok(t, err)
+	tok, err = store.Load(key)
+	ok(t, err)
+	equals(t, "access-2", tok.AccessToken)
+}
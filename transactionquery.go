@@ -0,0 +1,207 @@
+package dbapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// direction restricts transactions to credits or debits.
+type direction int
+
+const (
+	anyDirection direction = iota
+	creditsOnly
+	debitsOnly
+)
+
+// A TransactionQuery selects transactions. It is built by chaining its methods,
+// starting with NewTransactionQuery, and passed to TransactionsService.List:
+//
+//	q := dbapi.NewTransactionQuery().
+//		Account(iban).
+//		Between(from, to).
+//		Debits().
+//		CounterPartyName("netto")
+//	txs, _, err := client.Transactions.List(q)
+//
+// Conditions the API supports (the account) are sent as query parameters, all
+// others are evaluated locally on the returned transactions. The empty query
+// selects all transactions. A TransactionQuery must not be modified while it is
+// used.
+type TransactionQuery struct {
+	iban             IBAN
+	from, to         Date
+	min, max         *Money
+	direction        direction
+	counterPartyName string
+	counterPartyIBAN IBAN
+	usage            string
+	usageRe          *regexp.Regexp
+}
+
+// NewTransactionQuery returns an empty query, which selects all transactions.
+func NewTransactionQuery() *TransactionQuery {
+	return new(TransactionQuery)
+}
+
+// Account selects the transactions of the account with the given IBAN.
+func (q *TransactionQuery) Account(iban IBAN) *TransactionQuery {
+	q.iban = iban
+	return q
+}
+
+// Between selects transactions booked from the date from up to and including
+// the date to. A zero date leaves that end of the range open.
+func (q *TransactionQuery) Between(from, to Date) *TransactionQuery {
+	q.from, q.to = from, to
+	return q
+}
+
+// Since selects transactions booked on or after the date.
+func (q *TransactionQuery) Since(from Date) *TransactionQuery {
+	q.from = from
+	return q
+}
+
+// Until selects transactions booked on or before the date.
+func (q *TransactionQuery) Until(to Date) *TransactionQuery {
+	q.to = to
+	return q
+}
+
+// MinAmount selects transactions with an amount of at least min. Amounts are
+// signed, so debits have negative amounts.
+func (q *TransactionQuery) MinAmount(min Money) *TransactionQuery {
+	q.min = &min
+	return q
+}
+
+// MaxAmount selects transactions with an amount of at most max. Amounts are
+// signed, so debits have negative amounts.
+func (q *TransactionQuery) MaxAmount(max Money) *TransactionQuery {
+	q.max = &max
+	return q
+}
+
+// Credits selects transactions by which the user gained money.
+func (q *TransactionQuery) Credits() *TransactionQuery {
+	q.direction = creditsOnly
+	return q
+}
+
+// Debits selects transactions by which the user lost money.
+func (q *TransactionQuery) Debits() *TransactionQuery {
+	q.direction = debitsOnly
+	return q
+}
+
+// CounterPartyName selects transactions whose counterparty name contains the
+// name, ignoring case.
+func (q *TransactionQuery) CounterPartyName(name string) *TransactionQuery {
+	q.counterPartyName = strings.ToLower(name)
+	return q
+}
+
+// CounterPartyIBAN selects transactions with the counterparty IBAN. The IBAN
+// may be given in print format.
+func (q *TransactionQuery) CounterPartyIBAN(iban IBAN) *TransactionQuery {
+	q.counterPartyIBAN = iban.Normalize()
+	return q
+}
+
+// UsageContains selects transactions whose usage contains s, ignoring case.
+func (q *TransactionQuery) UsageContains(s string) *TransactionQuery {
+	q.usage = strings.ToLower(s)
+	return q
+}
+
+// UsageMatches selects transactions whose usage matches the regular expression.
+func (q *TransactionQuery) UsageMatches(re *regexp.Regexp) *TransactionQuery {
+	q.usageRe = re
+	return q
+}
+
+// Match reports whether the transaction is selected by the query. All
+// conditions of the query, including the ones which are sent to the API, are
+// evaluated.
+func (q *TransactionQuery) Match(t Transaction) bool {
+	switch {
+	case q == nil:
+		return true
+	case q.iban != "" && t.OriginIBAN != q.iban.Normalize():
+		return false
+	case !q.from.IsZero() && t.BookingDate.Before(q.from):
+		return false
+	case !q.to.IsZero() && t.BookingDate.After(q.to):
+		return false
+	case q.min != nil && t.Amount.Cmp(*q.min) < 0:
+		return false
+	case q.max != nil && t.Amount.Cmp(*q.max) > 0:
+		return false
+	case q.direction == creditsOnly && !t.IsCredit():
+		return false
+	case q.direction == debitsOnly && !t.IsDebit():
+		return false
+	case q.counterPartyName != "" && !strings.Contains(strings.ToLower(t.CounterPartyName), q.counterPartyName):
+		return false
+	case q.counterPartyIBAN != "" && t.CounterPartyIBAN != q.counterPartyIBAN:
+		return false
+	case q.usage != "" && !strings.Contains(strings.ToLower(t.Usage), q.usage):
+		return false
+	case q.usageRe != nil && !q.usageRe.MatchString(t.Usage):
+		return false
+	}
+	return true
+}
+
+// Filter returns the transactions which are selected by the query, in their
+// original order.
+func (q *TransactionQuery) Filter(t Transactions) Transactions {
+	return t.Filter(q.Match)
+}
+
+// values returns the conditions of the query which are supported by the API as
+// query parameters.
+func (q *TransactionQuery) values() url.Values {
+	v := url.Values{}
+	if q != nil && q.iban != "" {
+		v.Set("iban", string(q.iban))
+	}
+	return v
+}
+
+// List reads the transactions of the current user which are selected by the
+// query. A nil query selects all transactions. If the IBAN of the account is
+// malformed, an error wrapping ErrInvalidIBAN is returned without calling the
+// API. Its checksum isn't checked (see IBAN.ValidateStructure).
+func (s *TransactionsService) List(q *TransactionQuery) (*Transactions, *Response, error) {
+	return s.ListContext(context.Background(), q)
+}
+
+// ListContext is like List but uses the context ctx for the request.
+func (s *TransactionsService) ListContext(ctx context.Context, q *TransactionQuery) (*Transactions, *Response, error) {
+	if q != nil && q.iban != "" {
+		iban, err := parseRequestIBAN(q.iban)
+		if err != nil {
+			return nil, nil, err
+		}
+		c := *q
+		c.iban = iban
+		q = &c
+	}
+	u := "/transactions"
+	if v := q.values(); len(v) > 0 {
+		u += "?" + v.Encode()
+	}
+	r := new(Transactions)
+
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
+	if err != nil || q == nil {
+		return r, resp, err
+	}
+	*r = q.Filter(*r)
+	return r, resp, nil
+}
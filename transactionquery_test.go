@@ -0,0 +1,93 @@
+package dbapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestTransactionQuery_Match(t *testing.T) {
+	tx := Transaction{
+		OriginIBAN:       "DE10000000000000000454",
+		Amount:           eur("-35.56"),
+		CounterPartyName: "Netto Marken-Discount",
+		CounterPartyIBAN: "DE89370400440532013000",
+		Usage:            "POS MIT PIN. Einkauf 4711",
+		BookingDate:      mustParseDate("2016-10-27"),
+	}
+
+	mockData := []struct {
+		q   *TransactionQuery
+		exp bool
+	}{
+		{nil, true},
+		{NewTransactionQuery(), true},
+		{NewTransactionQuery().Account("DE10 0000 0000 0000 0004 54"), true},
+		{NewTransactionQuery().Account("DE10000000000000000455"), false},
+		{NewTransactionQuery().Between(mustParseDate("2016-10-27"), mustParseDate("2016-10-27")), true},
+		{NewTransactionQuery().Since(mustParseDate("2016-10-28")), false},
+		{NewTransactionQuery().Until(mustParseDate("2016-10-26")), false},
+		{NewTransactionQuery().Between(Date{}, mustParseDate("2016-10-31")), true},
+		{NewTransactionQuery().MinAmount(eur("-50")).MaxAmount(eur("-30")), true},
+		{NewTransactionQuery().MinAmount(eur("-30")), false},
+		{NewTransactionQuery().MaxAmount(eur("-50")), false},
+		{NewTransactionQuery().Debits(), true},
+		{NewTransactionQuery().Credits(), false},
+		{NewTransactionQuery().CounterPartyName("netto"), true},
+		{NewTransactionQuery().CounterPartyName("Lidl"), false},
+		{NewTransactionQuery().CounterPartyIBAN("de89 3704 0044 0532 0130 00"), true},
+		{NewTransactionQuery().CounterPartyIBAN("DE10000000000000000453"), false},
+		{NewTransactionQuery().UsageContains("einkauf"), true},
+		{NewTransactionQuery().UsageContains("Rechnung"), false},
+		{NewTransactionQuery().UsageMatches(regexp.MustCompile(`Einkauf \d+`)), true},
+		{NewTransactionQuery().UsageMatches(regexp.MustCompile(`^Einkauf`)), false},
+		{NewTransactionQuery().Debits().CounterPartyName("netto").Since(mustParseDate("2016-10-01")), true},
+	}
+
+	for _, tt := range mockData {
+		equals(t, tt.exp, tt.q.Match(tx))
+	}
+}
+
+func TestTransactionsService_List(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, http.MethodGet, r.Method)
+		equals(t, "DE10000000000000000454", r.URL.Query().Get("iban"))
+		fmt.Fprint(w, `[{"originIBAN":"DE10000000000000000454","amount":-35.56,"counterPartyName":"Netto","usage":"POS MIT PIN. Einkauf","bookingDate":"2016-10-27"},{"originIBAN":"DE10000000000000000454","amount":-52.22,"counterPartyName":"Lidl","usage":"POS MIT PIN. Einkauf","bookingDate":"2016-10-24"},{"originIBAN":"DE10000000000000000454","amount":-1500,"counterPartyName":"Schwäbisch Hall","usage":"Ref. 58974-8765889","bookingDate":"2016-10-21"}]`)
+	})
+
+	q := NewTransactionQuery().
+		Account("de10 0000 0000 0000 0004 54").
+		Since(mustParseDate("2016-10-22")).
+		UsageContains("pos mit pin")
+	act, _, err := testClient.Transactions.List(q)
+	ok(t, err)
+	equals(t, []string{"Netto", "Lidl"}, counterParties(*act))
+}
+
+func TestTransactionsService_List_NilQuery(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, "", r.URL.RawQuery)
+		fmt.Fprint(w, `[{"originIBAN":"DE10000000000000000454","amount":-35.56,"counterPartyName":"Netto","bookingDate":"2016-10-27"}]`)
+	})
+
+	act, _, err := testClient.Transactions.List(nil)
+	ok(t, err)
+	equals(t, []string{"Netto"}, counterParties(*act))
+}
+
+func TestTransactionsService_List_InvalidIBAN(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, _, err := testClient.Transactions.List(NewTransactionQuery().Account("DE0000000000000000000X"))
+	assert(t, errors.Is(err, ErrInvalidIBAN), "expected ErrInvalidIBAN, got %v", err)
+}
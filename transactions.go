@@ -1,8 +1,10 @@
 package dbapi
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
 )
 
 // The TransactionsService binds to the HTTP endpoints which belong to
@@ -11,37 +13,129 @@ type TransactionsService struct {
 	client *Client
 }
 
-// Transactions are the users transactions.
-type Transactions []struct {
-	OriginIBAN       string  `json:"originIban,omitempty"`
-	Amount           float64 `json:"amount,omitempty"`
-	CounterPartyName string  `json:"counterPartyName,omitempty"`
-	CounterPartyIBAN string  `json:"counterPartyIban,omitempty"`
-	Usage            string  `json:"usage,omitempty"`
-	BookingDate      string  `json:"bookingDate,omitempty"`
+// A Transaction is a booking on a cash account of the user. A positive amount
+// means the user gained money, a negative amount means the user lost money.
+type Transaction struct {
+	OriginIBAN       IBAN   `json:"originIban,omitempty"`
+	Amount           Money  `json:"amount,omitempty"`
+	CounterPartyName string `json:"counterPartyName,omitempty"`
+	CounterPartyIBAN IBAN   `json:"counterPartyIban,omitempty"`
+	Usage            string `json:"usage,omitempty"`
+	BookingDate      Date   `json:"bookingDate,omitempty"`
 }
 
+// Transactions are the users transactions.
+type Transactions []Transaction
+
 // GetAll reads all transactions of all accounts of the current user. It is
 // not apparent who issued a transaction, only whether the user gained or lost
 // money by it (based on whether the amount is positive or negative
 // respectively).
 func (s *TransactionsService) GetAll() (*Transactions, *Response, error) {
+	return s.GetAllContext(context.Background())
+}
+
+// GetAllContext is like GetAll but uses the context ctx for the request.
+func (s *TransactionsService) GetAllContext(ctx context.Context) (*Transactions, *Response, error) {
 	u := "/transactions"
 	r := new(Transactions)
 
-	resp, err := s.client.Call(http.MethodGet, u, nil, r)
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
 	return r, resp, err
 }
 
 // Get all transactions for a specific account of the current user. If given
-// IBAN is not valid or does not represent an account of the current user, an
-// empty result is returned. It is not apparent who issued a transaction, only
-// whether the user gained or lost money by it (based on whether the amount is
-// positive or negative respectively).
-func (s *TransactionsService) Get(iban string) (*Transactions, *Response, error) {
+// IBAN is malformed, an error wrapping ErrInvalidIBAN is returned without
+// calling the API. Its checksum isn't checked (see IBAN.ValidateStructure). If
+// it does not represent an account of the current user, an empty result is
+// returned. It is not apparent who issued a transaction, only whether the user
+// gained or lost money by it (based on whether the amount is positive or
+// negative respectively).
+func (s *TransactionsService) Get(iban IBAN) (*Transactions, *Response, error) {
+	return s.GetContext(context.Background(), iban)
+}
+
+// GetContext is like Get but uses the context ctx for the request.
+func (s *TransactionsService) GetContext(ctx context.Context, iban IBAN) (*Transactions, *Response, error) {
+	iban, err := parseRequestIBAN(iban)
+	if err != nil {
+		return nil, nil, err
+	}
 	u := fmt.Sprintf("/transactions?iban=%s", iban)
 	r := new(Transactions)
 
-	resp, err := s.client.Call(http.MethodGet, u, nil, r)
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
 	return r, resp, err
 }
+
+// IsCredit reports whether the user gained money by the transaction.
+func (t Transaction) IsCredit() bool {
+	return t.Amount.IsPositive()
+}
+
+// IsDebit reports whether the user lost money by the transaction.
+func (t Transaction) IsDebit() bool {
+	return t.Amount.IsNegative()
+}
+
+// ByIBAN returns the transactions of the account with the given IBAN.
+func (t Transactions) ByIBAN(iban IBAN) Transactions {
+	return t.Filter(func(tx Transaction) bool { return tx.OriginIBAN == iban })
+}
+
+// Filter returns the transactions for which keep returns true, in their
+// original order.
+func (t Transactions) Filter(keep func(Transaction) bool) Transactions {
+	var r Transactions
+	for _, tx := range t {
+		if keep(tx) {
+			r = append(r, tx)
+		}
+	}
+	return r
+}
+
+// SortByDate sorts the transactions by booking date, oldest first. The order of
+// transactions booked on the same date is preserved.
+func (t Transactions) SortByDate() {
+	sort.SliceStable(t, func(i, j int) bool {
+		return t[i].BookingDate.Before(t[j].BookingDate)
+	})
+}
+
+// SortByAmount sorts the transactions by amount, smallest (i.e. the largest
+// debit) first. The order of transactions with the same amount is preserved.
+func (t Transactions) SortByAmount() {
+	sort.SliceStable(t, func(i, j int) bool {
+		return t[i].Amount.Cmp(t[j].Amount) < 0
+	})
+}
+
+// GroupByAccount groups the transactions by the IBAN of their account. The
+// order of the transactions is preserved within each group.
+func (t Transactions) GroupByAccount() map[IBAN]Transactions {
+	groups := make(map[IBAN]Transactions)
+	for _, tx := range t {
+		groups[tx.OriginIBAN] = append(groups[tx.OriginIBAN], tx)
+	}
+	return groups
+}
+
+// Total returns the sum of the amounts of the transactions.
+func (t Transactions) Total() Money {
+	var total Money
+	for _, tx := range t {
+		total = total.Add(tx.Amount)
+	}
+	return total
+}
+
+// Income returns the sum of the amounts of all credits.
+func (t Transactions) Income() Money {
+	return t.Filter(Transaction.IsCredit).Total()
+}
+
+// Spending returns the sum of the amounts of all debits as positive amount.
+func (t Transactions) Spending() Money {
+	return t.Filter(Transaction.IsDebit).Total().Neg()
+}
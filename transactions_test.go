@@ -1,6 +1,7 @@
 package dbapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
@@ -11,12 +12,12 @@ func TestTransactionsService_GetAll(t *testing.T) {
 	defer teardown()
 
 	exp := &Transactions{
-		{OriginIBAN: "DE10000000000000000454", Amount: -35.56, CounterPartyName: "Netto", Usage: "POS MIT PIN. Einkauf", BookingDate: "2016-10-27"},
-		{OriginIBAN: "DE10000000000000000454", Amount: -52.22, CounterPartyName: "Lidl", Usage: "POS MIT PIN. Einkauf", BookingDate: "2016-10-24"},
-		{OriginIBAN: "DE10000000000000000454", Amount: -1500, CounterPartyName: "Schwäbisch Hall", Usage: "Ref. 58974-8765889", BookingDate: "2016-10-21"},
-		{OriginIBAN: "DE10000000000000000454", Amount: -38.98, CounterPartyName: "Toys R Us", Usage: "Rechnung", BookingDate: "2016-10-17"},
-		{OriginIBAN: "DE10000000000000000454", Amount: -25.95, CounterPartyName: "Alnatura Frankfurt", Usage: "POS MIT PIN. Einkauf", BookingDate: "2016-10-17"},
-		{OriginIBAN: "DE10000000000000000454", Amount: -96.16, CounterPartyName: "JET", Usage: "POS MIT PIN. Die Tanke Ihrer Wahl", BookingDate: "2016-10-12"},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-35.56"), CounterPartyName: "Netto", Usage: "POS MIT PIN. Einkauf", BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-52.22"), CounterPartyName: "Lidl", Usage: "POS MIT PIN. Einkauf", BookingDate: mustParseDate("2016-10-24")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", Usage: "Ref. 58974-8765889", BookingDate: mustParseDate("2016-10-21")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-38.98"), CounterPartyName: "Toys R Us", Usage: "Rechnung", BookingDate: mustParseDate("2016-10-17")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-25.95"), CounterPartyName: "Alnatura Frankfurt", Usage: "POS MIT PIN. Einkauf", BookingDate: mustParseDate("2016-10-17")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-96.16"), CounterPartyName: "JET", Usage: "POS MIT PIN. Die Tanke Ihrer Wahl", BookingDate: mustParseDate("2016-10-12")},
 	}
 
 	testMux.HandleFunc("/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
@@ -34,8 +35,8 @@ func TestTransactionsService_Get(t *testing.T) {
 	defer teardown()
 
 	exp := &Transactions{
-		{OriginIBAN: "DE10000000000000000455", Amount: 50, CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: "2016-10-01"},
-		{OriginIBAN: "DE10000000000000000455", Amount: 50, CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: "2016-09-01"},
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", Usage: "Sparen Samuel", BookingDate: mustParseDate("2016-09-01")},
 	}
 
 	testMux.HandleFunc("/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
@@ -47,3 +48,68 @@ func TestTransactionsService_Get(t *testing.T) {
 	ok(t, err)
 	equals(t, exp, act)
 }
+
+func TestTransactionsService_Get_InvalidIBAN(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be made.")
+	})
+
+	_, resp, err := testClient.Transactions.Get("not an iban")
+	assert(t, errors.Is(err, ErrInvalidIBAN), "Expected ErrInvalidIBAN, got %v.", err)
+	assert(t, resp == nil, "Expected no response.")
+}
+
+func testTransactions() Transactions {
+	return Transactions{
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-35.56"), CounterPartyName: "Netto", BookingDate: mustParseDate("2016-10-27")},
+		{OriginIBAN: "DE10000000000000000455", Amount: eur("50"), CounterPartyName: "Claudia Klar", BookingDate: mustParseDate("2016-10-01")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-1500"), CounterPartyName: "Schwäbisch Hall", BookingDate: mustParseDate("2016-10-21")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-25.95"), CounterPartyName: "Alnatura Frankfurt", BookingDate: mustParseDate("2016-10-17")},
+		{OriginIBAN: "DE10000000000000000454", Amount: eur("-38.98"), CounterPartyName: "Toys R Us", BookingDate: mustParseDate("2016-10-17")},
+	}
+}
+
+func counterParties(t Transactions) []string {
+	names := make([]string, len(t))
+	for i, tx := range t {
+		names[i] = tx.CounterPartyName
+	}
+	return names
+}
+
+func TestTransactions_Filter(t *testing.T) {
+	txs := testTransactions()
+
+	equals(t, []string{"Claudia Klar"}, counterParties(txs.Filter(Transaction.IsCredit)))
+	equals(t, []string{"Claudia Klar"}, counterParties(txs.ByIBAN("DE10000000000000000455")))
+	equals(t, 0, len(txs.ByIBAN("DE89370400440532013000")))
+}
+
+func TestTransactions_Sort(t *testing.T) {
+	txs := testTransactions()
+
+	txs.SortByDate()
+	equals(t, []string{"Claudia Klar", "Alnatura Frankfurt", "Toys R Us", "Schwäbisch Hall", "Netto"}, counterParties(txs))
+
+	txs.SortByAmount()
+	equals(t, []string{"Schwäbisch Hall", "Toys R Us", "Netto", "Alnatura Frankfurt", "Claudia Klar"}, counterParties(txs))
+}
+
+func TestTransactions_GroupByAccount(t *testing.T) {
+	groups := testTransactions().GroupByAccount()
+
+	equals(t, 2, len(groups))
+	equals(t, []string{"Netto", "Schwäbisch Hall", "Alnatura Frankfurt", "Toys R Us"}, counterParties(groups["DE10000000000000000454"]))
+	equals(t, []string{"Claudia Klar"}, counterParties(groups["DE10000000000000000455"]))
+}
+
+func TestTransactions_Totals(t *testing.T) {
+	txs := testTransactions()
+
+	equals(t, eur("-1550.49"), txs.Total())
+	equals(t, eur("50"), txs.Income())
+	equals(t, eur("1600.49"), txs.Spending())
+}
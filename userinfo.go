@@ -1,8 +1,15 @@
 package dbapi
 
-import "net/http"
+import (
+	"context"
+	"errors"
+	"net/http"
+)
 
 // The UserInfoService binds to the HTTP endpoints which belong to the userInfo resource.
+//
+// Deprecated: The userInfo resource is replaced by the partners resource. Use
+// the PartnersService instead.
 type UserInfoService struct {
 	client *Client
 }
@@ -11,16 +18,33 @@ type UserInfoService struct {
 type UserInfo struct {
 	FirstName   string `json:"firstName,omitempty"`
 	LastName    string `json:"lastName,omitempty"`
-	DateOfBirth string `json:"dateOfBirth,omitempty"`
-	Gender      string `json:"gender,omitempty"`
+	DateOfBirth Date   `json:"dateOfBirth,omitempty"`
+	Gender      Gender `json:"gender,omitempty"`
 }
 
 // Get retrieves personal information (e.g. first name, family name date of
-// birth) about the current user.
+// birth) about the current user. If the userInfo resource isn't available
+// anymore, the information is retrieved from the partners resource instead.
+//
+// Deprecated: Use PartnersService.Get instead.
 func (s *UserInfoService) Get() (*UserInfo, *Response, error) {
+	return s.GetContext(context.Background())
+}
+
+// GetContext is like Get but uses the context ctx for the request.
+//
+// Deprecated: Use PartnersService.GetContext instead.
+func (s *UserInfoService) GetContext(ctx context.Context) (*UserInfo, *Response, error) {
 	u := "/userInfo"
 	r := new(UserInfo)
 
-	resp, err := s.client.Call(http.MethodGet, u, nil, r)
+	resp, err := s.client.CallContext(ctx, http.MethodGet, u, nil, r)
+	if errors.Is(err, ErrNotFound) {
+		p, resp, err := s.client.Partners.GetContext(ctx)
+		if err != nil {
+			return r, resp, err
+		}
+		return p.UserInfo(), resp, nil
+	}
 	return r, resp, err
 }
@@ -10,7 +10,7 @@ func TestUserInfoService_Get(t *testing.T) {
 	setup()
 	defer teardown()
 
-	exp := &UserInfo{FirstName: "Claudia", LastName: "Klar", Gender: "FEMALE", DateOfBirth: "1977-03-02"}
+	exp := &UserInfo{FirstName: "Claudia", LastName: "Klar", Gender: "FEMALE", DateOfBirth: mustParseDate("1977-03-02")}
 
 	testMux.HandleFunc("/v1/userInfo", func(w http.ResponseWriter, r *http.Request) {
 		equals(t, http.MethodGet, r.Method)
@@ -21,3 +21,22 @@ func TestUserInfoService_Get(t *testing.T) {
 	ok(t, err)
 	equals(t, exp, act)
 }
+
+func TestUserInfoService_Get_Partners(t *testing.T) {
+	setup()
+	defer teardown()
+
+	exp := &UserInfo{FirstName: "Claudia", LastName: "Klar", Gender: "FEMALE", DateOfBirth: mustParseDate("1977-03-02")}
+
+	testMux.HandleFunc("/v1/userInfo", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	testMux.HandleFunc("/v1/partners", func(w http.ResponseWriter, r *http.Request) {
+		equals(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"partnerType":"NATURAL_PERSON","naturalPerson":{"firstName":"Claudia","lastName":"Klar","dateOfBirth":"1977-03-02","gender":"FEMALE"}}`)
+	})
+
+	act, _, err := testClient.UserInfo.Get()
+	ok(t, err)
+	equals(t, exp, act)
+}